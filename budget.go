@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- BUDGET ---
+// The monthly limit is stored as the single item of the "Budget" category.
+const budgetItemID = "monthly"
+
+type BudgetItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Limit float64 `json:"limit"` // 0 = no budget set
+}
+
+func (b *BudgetItem) itemID() *string  { return &b.ID }
+func (b *BudgetItem) itemName() string { return b.Name }
+
+// cycleMonths is how many months one payment of each subscription cycle covers.
+var cycleMonths = map[string]float64{
+	"Monthly":  1,
+	"3 Months": 3,
+	"Yearly":   12,
+}
+
+// monthlyCost normalises a subscription's price to a per-month figure.
+func (s SubItem) monthlyCost() float64 {
+	months, ok := cycleMonths[s.Cycle]
+	if !ok {
+		months = 1
+	}
+	return s.Price / months
+}
+
+type budgetSummary struct {
+	subscriptions float64
+	groceries     float64
+	limit         float64
+}
+
+func (b budgetSummary) total() float64 { return b.subscriptions + b.groceries }
+
+func (m model) budgetSummary() budgetSummary {
+	b := budgetSummary{groceries: monthlySpend(m.orderHistory, time.Now()), limit: m.budget.Limit}
+	for _, s := range m.subItems {
+		b.subscriptions += s.monthlyCost()
+	}
+	return b
+}
+
+// budgetAlerts returns the ACTION REQUIRED lines for the budget, if any.
+func (m model) budgetAlerts() []string {
+	b := m.budgetSummary()
+	if b.limit <= 0 {
+		return nil
+	}
+	switch {
+	case b.total() > b.limit:
+		line := fmt.Sprintf("💸 OVER BUDGET: $%.2f of $%.2f this month", b.total(), b.limit)
+		return []string{lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C")).Render(line)}
+	case b.total() >= 0.9*b.limit:
+		line := fmt.Sprintf("💸 NEAR BUDGET: $%.2f of $%.2f this month", b.total(), b.limit)
+		return []string{lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(line)}
+	}
+	return nil
+}
+
+// --- VIEW ---
+func (m model) viewBudget() string {
+	s := titleStyle.Render("📊 BUDGET - "+strings.ToUpper(time.Now().Format("January 2006"))) + "\n"
+	b := m.budgetSummary()
+
+	s += "Subscriptions (per month):\n"
+	if len(m.subItems) == 0 {
+		s += "    None.\n"
+	}
+	for _, sub := range m.subItems {
+		nameCol := lipgloss.NewStyle().Width(18).Render(sub.Name)
+		s += fmt.Sprintf("    %s $%7.2f  %s\n", nameCol, sub.monthlyCost(), hintStyle.Render(fmt.Sprintf("($%.2f %s)", sub.Price, sub.Cycle)))
+	}
+
+	s += fmt.Sprintf("\n  %-20s $%7.2f\n", "Subscriptions", b.subscriptions)
+	s += fmt.Sprintf("  %-20s $%7.2f\n", "Groceries (orders)", b.groceries)
+	s += fmt.Sprintf("  %-20s $%7.2f\n\n", "Total", b.total())
+
+	if b.limit <= 0 {
+		s += hintStyle.Render("  No monthly budget set. Press 'b' to set one.") + "\n"
+	} else {
+		color := "#04B575"
+		status := fmt.Sprintf("$%.2f left", b.limit-b.total())
+		if b.total() > b.limit {
+			color = "#FF4C4C"
+			status = fmt.Sprintf("OVER by $%.2f", b.total()-b.limit)
+		} else if b.total() >= 0.9*b.limit {
+			color = "#E1B12C"
+		}
+		s += fmt.Sprintf("  Budget: $%.2f  %s\n", b.limit, lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true).Render(status))
+		s += "  " + budgetBar(b.total(), b.limit, 40, color) + "\n"
+	}
+
+	s += "\n" + hintStyle.Render("[b: Set budget • Esc: Back]")
+	s += "\n" + m.renderStatus()
+	return s
+}
+
+// budgetBar draws a fixed-width gauge of spent against limit.
+func budgetBar(spent, limit float64, width int, color string) string {
+	filled := int(spent / limit * float64(width))
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	bar := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(strings.Repeat("█", filled))
+	return bar + hintStyle.Render(strings.Repeat("░", width-filled)) + fmt.Sprintf(" %3.0f%%", spent/limit*100)
+}
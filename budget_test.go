@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSaveBudgetRejectsBadLimit(t *testing.T) {
+	for _, in := range []string{"abc", "-5", "NaN", "inf", "-Inf"} {
+		m := model{state: stateEditBudget, budget: BudgetItem{Limit: 300}}
+		m.initForm(stateEditBudget, true)
+		m.inputs[0].SetValue(in)
+		if _, err := m.saveForm(); err == nil {
+			t.Errorf("%q: saved", in)
+		}
+		if m.budget.Limit != 300 {
+			t.Errorf("%q: limit changed to %v", in, m.budget.Limit)
+		}
+		if _, bad := m.fieldErrs[0]; !bad {
+			t.Errorf("%q: no inline error", in)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"tui/client"
+)
+
+// --- HEADLESS CLI ---
+// Subcommands for scripts and cron. They drive the same model, commands and
+// outbox as the TUI, so a write that cannot reach the backend is queued and
+// replayed by the next run of either.
+const cliUsage = `Usage: tui [global flags] <command> [flags]
+
+Commands:
+  food list [--low]                                  List pantry items
+  food add --name N [--price P] [--amount A] [--threshold T]
+                                                     Add a pantry item
+  subs list                                          List subscriptions
+  subs due [--within 3d]                             Subscriptions due soon
+  study list                                         List assignments
+  study sync                                         Scrape Canvas now
+  push                                               Send the grocery list to the phone
+
+Every command accepts --json for machine-readable output.
+`
+
+type cli struct {
+	m      model
+	out    io.Writer
+	errOut io.Writer
+}
+
+// runCLI runs a subcommand and returns the process exit code.
+func runCLI(m model, args []string) int {
+	c := &cli{m: m, out: os.Stdout, errOut: os.Stderr}
+	if err := c.run(args); err != nil {
+		fmt.Fprintln(c.errOut, "❌ Error:", err)
+		if errors.Is(err, flag.ErrHelp) || errors.Is(err, errUsage) {
+			fmt.Fprint(c.errOut, cliUsage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+var errUsage = errors.New("unknown command")
+
+func (c *cli) run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd := args[0]
+	if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+		cmd += " " + args[1]
+		args = args[2:]
+	} else {
+		args = args[1:]
+	}
+
+	switch cmd {
+	case "food list":
+		return c.foodList(args)
+	case "food add":
+		return c.foodAdd(args)
+	case "subs list":
+		return c.subsList(args)
+	case "subs due":
+		return c.subsDue(args)
+	case "study list":
+		return c.studyList(args)
+	case "study sync":
+		return c.studySync(args)
+	case "push":
+		return c.push(args)
+	case "help":
+		fmt.Fprint(c.out, cliUsage)
+		return nil
+	}
+	return fmt.Errorf("%w %q", errUsage, cmd)
+}
+
+// --- DATA ACCESS ---
+
+// load fetches categories through the model, falling back to the local cache
+// when the backend is unreachable, then flushes any queued writes.
+func (c *cli) load() error {
+	msg := fetchCategoriesCmd(c.m.ctx, c.m.api, c.m.token)()
+	if failed, ok := msg.(fetchFailedMsg); ok {
+		if !isOfflineErr(failed.err) || len(c.m.store.Categories) == 0 {
+			return failed.err
+		}
+		fmt.Fprintln(c.errOut, "⚠️ Backend unreachable, using cached data:", failed.err)
+		c.apply(msg)
+		return nil
+	}
+	// A fresh fetch replays the outbox (and any due-date rollover) as sync commands
+	c.runSync(c.apply(msg))
+	return nil
+}
+
+// apply feeds msg to the model and returns its follow-up command. Callers only
+// run the sync commands they expect; ticks and refetches would just block.
+func (c *cli) apply(msg tea.Msg) tea.Cmd {
+	next, cmd := c.m.Update(msg)
+	c.m = next.(model)
+	return cmd
+}
+
+// runSync executes sync commands (possibly batched) and applies their results.
+// It returns the last sync result, if any.
+func (c *cli) runSync(cmd tea.Cmd) tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	var last tea.Msg
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, sub := range msg {
+			if r := c.runSync(sub); r != nil {
+				last = r
+			}
+		}
+	case syncSuccessMsg, syncFailedMsg:
+		c.apply(msg)
+		last = msg
+	}
+	return last
+}
+
+// save queues a write for the named category and reports how it went.
+func (c *cli) save(name string, items interface{}) error {
+	switch r := c.runSync(c.m.queueSync(name, items)).(type) {
+	case syncFailedMsg:
+		if client.IsConflict(r.err) {
+			return fmt.Errorf("%s changed on the server; open the dashboard to resolve the conflict", name)
+		}
+		if isOfflineErr(r.err) {
+			fmt.Fprintln(c.errOut, "⚠️ Backend unreachable; change queued and will sync on the next run")
+			return nil
+		}
+		return r.err
+	case nil:
+		if c.m.offline {
+			fmt.Fprintln(c.errOut, "⚠️ Offline; change queued and will sync on the next run")
+		}
+	}
+	return nil
+}
+
+// --- COMMANDS ---
+
+func (c *cli) flags(name string) (*flag.FlagSet, *bool) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	asJSON := fs.Bool("json", false, "Print JSON instead of a table")
+	return fs, asJSON
+}
+
+func (c *cli) foodList(args []string) error {
+	fs, asJSON := c.flags("food list")
+	low := fs.Bool("low", false, "Only items at or below their renew threshold")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	items := []FoodItem{}
+	for _, f := range c.m.foodItems {
+		if !*low || (f.RenewThreshold > 0 && f.Amount <= f.RenewThreshold) {
+			items = append(items, f)
+		}
+	}
+	if *asJSON {
+		return c.printJSON(items)
+	}
+	return c.table([]string{"NAME", "STOCK", "PRICE", "RENEW AT", "EXPIRES"}, len(items), func(i int) []string {
+		f := items[i]
+		expires := "-"
+		if b, ok := f.nextExpiry(); ok {
+			expires = fmt.Sprintf("%s %s (%s)", qtyLabel(b.Qty, f.Unit), b.Expires.InputString(), expiryText(b.Expires))
+		}
+		return []string{f.Name, amountText(f.Amount, f.Unit), f.priceText(), fmtQty(f.RenewThreshold), expires}
+	})
+}
+
+// foodFlags names the food add flag behind each food form field, for errors.
+var foodFlags = map[int]string{
+	foodBarcode:   "--barcode",
+	foodName:      "--name",
+	foodPrice:     "--price",
+	foodAmount:    "--amount",
+	foodThreshold: "--threshold",
+	foodUnit:      "--unit",
+	foodPackSize:  "--pack",
+}
+
+func (c *cli) foodAdd(args []string) error {
+	fs, asJSON := c.flags("food add")
+	name := fs.String("name", "", "Item name (required unless --barcode finds it)")
+	price := fs.String("price", "", "Price per unit")
+	amount := fs.String("amount", "", "Current stock amount")
+	threshold := fs.String("threshold", "", "Reorder threshold (0 = disabled)")
+	unitFlag := fs.String("unit", unitPieces, "Stock unit: "+strings.Join(stockUnits, ", "))
+	pack := fs.String("pack", "", "Package size in units; --price is then per package")
+	aisle := fs.String("aisle", "", "Supermarket aisle: "+strings.Join(aisleOrder, ", "))
+	storage := fs.String("storage", "", "Storage location: "+strings.Join(storageOrder, ", "))
+	barcode := fs.String("barcode", "", "EAN/UPC looked up in the product database to fill in the flags not given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *barcode != "" {
+		p, err := lookupProduct(c.m.cfg.Products.File, *barcode)
+		if err != nil {
+			return fmt.Errorf("food add: barcode %s: %w", *barcode, err)
+		}
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if !set["name"] {
+			*name = p.displayName()
+		}
+		if !set["price"] && p.Price > 0 {
+			*price = fmtQty(p.Price)
+		}
+		if u, size, ok := p.packaging(); ok && !set["unit"] && !set["pack"] {
+			*unitFlag, *pack = u, fmtQty(size)
+		}
+		if !set["aisle"] {
+			*aisle = p.aisle()
+		}
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	// Same rules as the food form: unique names and barcodes, real numbers
+	vals := make([]string, foodFieldCount)
+	vals[foodBarcode], vals[foodName], vals[foodPrice] = *barcode, *name, *price
+	vals[foodAmount], vals[foodThreshold], vals[foodUnit] = *amount, *threshold, *unitFlag
+	vals[foodPackSize], vals[foodAisle], vals[foodStorage] = *pack, *aisle, *storage
+	c.m.editIndex = -1
+	item, errs := c.m.validateFood(vals, 0)
+	if len(errs) > 0 {
+		var msgs []string
+		for _, i := range errs.fields() {
+			msgs = append(msgs, foodFlags[i]+": "+errs[i])
+		}
+		return errors.New("food add: " + strings.Join(msgs, "; "))
+	}
+
+	status := c.m.putFoodItem(item)
+	item = c.m.foodItems[len(c.m.foodItems)-1]
+	if err := c.save("Food", c.m.foodItems); err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(item)
+	}
+	fmt.Fprintf(c.out, "✅ Added %s (stock %s, %s)\n", item.Name, amountText(item.Amount, item.Unit), item.priceText())
+	if status != "" {
+		fmt.Fprintln(c.out, status)
+	}
+	return nil
+}
+
+func (c *cli) subsDue(args []string) error {
+	fs, asJSON := c.flags("subs due")
+	within := fs.String("within", "3d", "Window such as 3d, 2w or 36h")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	days, err := parseWithin(*within)
+	if err != nil {
+		return err
+	}
+	return c.printSubs(days, *asJSON)
+}
+
+func (c *cli) subsList(args []string) error {
+	fs, asJSON := c.flags("subs list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return c.printSubs(-1, *asJSON)
+}
+
+// printSubs prints subscriptions; withinDays >= 0 keeps only those due in that
+// many days (0 = today), a negative value lists them all.
+func (c *cli) printSubs(withinDays int, asJSON bool) error {
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	items := []SubItem{}
+	for _, s := range c.m.subItems {
+		d := s.DueDate.daysUntil()
+		if withinDays < 0 || (!s.DueDate.IsZero() && d >= 0 && d <= withinDays) {
+			items = append(items, s)
+		}
+	}
+	if asJSON {
+		return c.printJSON(items)
+	}
+	return c.table([]string{"NAME", "CYCLE", "PRICE", "DUE", "IN"}, len(items), func(i int) []string {
+		s := items[i]
+		in := "-"
+		if !s.DueDate.IsZero() {
+			in = fmt.Sprintf("%dd", s.DueDate.daysUntil())
+		}
+		return []string{s.Name, s.Cycle, fmt.Sprintf("$%.2f", s.Price), s.DueDate.String(), in}
+	})
+}
+
+func (c *cli) studyList(args []string) error {
+	fs, asJSON := c.flags("study list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+	return c.printStudy(c.m.studyItems, *asJSON)
+}
+
+func (c *cli) studySync(args []string) error {
+	fs, asJSON := c.flags("study sync")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch msg := scrapeCanvasCmd(c.m.ctx, c.m.api, c.m.token)().(type) {
+	case errMsg:
+		return msg.err
+	case canvasScrapedMsg:
+		if !*asJSON {
+			fmt.Fprintf(c.errOut, "✅ Canvas sync complete: %d assignment(s)\n", len(msg))
+		}
+		return c.printStudy(msg, *asJSON)
+	}
+	return nil
+}
+
+func (c *cli) printStudy(items []StudyItem, asJSON bool) error {
+	if items == nil {
+		items = []StudyItem{}
+	}
+	if asJSON {
+		return c.printJSON(items)
+	}
+	return c.table([]string{"ASSIGNMENT", "DUE"}, len(items), func(i int) []string {
+		return []string{items[i].Name, items[i].DueDate}
+	})
+}
+
+func (c *cli) push(args []string) error {
+	fs, asJSON := c.flags("push")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+	if msg, ok := pushGroceryListCmd(c.m.cfg, c.m.foodItems)().(errMsg); ok {
+		return msg.err
+	}
+	if *asJSON {
+		return c.printJSON(map[string]bool{"sent": true})
+	}
+	fmt.Fprintln(c.out, "📲 Sent to your phone!")
+	return nil
+}
+
+// --- OUTPUT ---
+
+func (c *cli) printJSON(v interface{}) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) table(header []string, rows int, row func(i int) []string) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for i := 0; i < rows; i++ {
+		fmt.Fprintln(w, strings.Join(row(i), "\t"))
+	}
+	return w.Flush()
+}
+
+// parseWithin reads a window like "3d", "2w" or any time.Duration ("36h"), in
+// whole days; anything under a day means due today.
+func parseWithin(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	days := -1
+	if n, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") {
+		days = n
+	} else if n, err := strconv.Atoi(strings.TrimSuffix(s, "w")); err == nil && strings.HasSuffix(s, "w") {
+		days = n * 7
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		days = int(d.Hours() / 24)
+	}
+	if days < 0 {
+		return 0, fmt.Errorf("invalid --within %q (use e.g. 3d, 2w or 36h)", s)
+	}
+	return days, nil
+}
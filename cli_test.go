@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tui/client"
+)
+
+func TestParseWithin(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"3d", 3, false},
+		{" 2w ", 14, false},
+		{"36h", 1, false},
+		{"12h", 0, false},
+		{"0d", 0, false},
+		{"-1d", 0, true},
+		{"-48h", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseWithin(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseWithin(%q) = %d, %v; want %d (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// newTestCLI runs commands against a fake backend holding the given categories.
+func newTestCLI(t *testing.T, cats ...client.Category) (*cli, *bytes.Buffer) {
+	t.Helper()
+	api := newFakeAPI(cats...)
+	m := initialModel(context.Background(), Config{}, api, nil, nil, &localStore{}, "u")
+	out := new(bytes.Buffer)
+	return &cli{m: m, out: out, errOut: new(bytes.Buffer)}, out
+}
+
+func category(t *testing.T, id, name string, items any) client.Category {
+	t.Helper()
+	content, err := json.Marshal(map[string]any{"items": items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.Category{Id: id, Name: name, Content: content, Version: 1}
+}
+
+func TestSubsDueWithin(t *testing.T) {
+	subs := []SubItem{
+		{Name: "Today", Cycle: "Monthly", DueDate: today()},
+		{Name: "Tomorrow", Cycle: "Monthly", DueDate: Date{today().AddDate(0, 0, 1)}},
+		{Name: "TBD", Cycle: "Monthly"},
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--within", "0d"}, []string{"Today"}},
+		{[]string{"--within", "12h"}, []string{"Today"}},
+		{[]string{"--within", "1d"}, []string{"Today", "Tomorrow"}},
+	}
+	for _, tt := range tests {
+		c, out := newTestCLI(t, category(t, "s", "Subscriptions", subs))
+		if err := c.subsDue(append(tt.args, "--json")); err != nil {
+			t.Fatal(err)
+		}
+		var got []SubItem
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("%v: %v in %s", tt.args, err, out)
+		}
+		var names []string
+		for _, s := range got {
+			names = append(names, s.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%v listed %v, want %v", tt.args, names, tt.want)
+		}
+	}
+
+	c, out := newTestCLI(t, category(t, "s", "Subscriptions", subs))
+	if err := c.subsList([]string{"--json"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), `"name"`); n != len(subs) {
+		t.Errorf("subs list showed %d of %d", n, len(subs))
+	}
+}
+
+func TestFoodAdd(t *testing.T) {
+	pantry := []FoodItem{{ID: "m", Name: "Milk"}}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string // substring; "" for success
+	}{
+		{"valid", []string{"--name", "Flour", "--price", "1.20", "--amount", "2", "--unit", "kg"}, ""},
+		{"missing name", []string{"--price", "1"}, "--name: a name is required"},
+		{"duplicate name", []string{"--name", "milk"}, `--name: "Milk" already exists`},
+		{"NaN", []string{"--name", "Flour", "--price", "NaN", "--amount", "inf"}, `--price: "NaN" is not a number; --amount: "inf" is not a number`},
+		{"negative", []string{"--name", "Flour", "--threshold", "-1"}, "--threshold: can't be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestCLI(t, category(t, "f", "Food", pantry))
+			err := c.foodAdd(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(c.m.foodItems) != 2 {
+					t.Errorf("pantry has %d items", len(c.m.foodItems))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if len(c.m.foodItems) != 1 {
+				t.Errorf("rejected item was added: %v", c.m.foodItems)
+			}
+		})
+	}
+}
+
+func TestFoodAddRunsReorderRules(t *testing.T) {
+	c, out := newTestCLI(t, category(t, "f", "Food", []FoodItem{}))
+	if err := c.foodAdd([]string{"--name", "Eggs", "--amount", "1", "--threshold", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.m.foodItems[0].CartQty == 0 {
+		t.Error("low item was not put in the cart")
+	}
+	if !strings.Contains(out.String(), "🔁 Reorder") {
+		t.Errorf("output = %q", out)
+	}
+}
@@ -0,0 +1,298 @@
+// Package client is a typed wrapper around the dashboard backend's HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTimeout       = 10 * time.Second
+	DefaultScrapeTimeout = 2 * time.Minute
+
+	maxErrorBody = 512 // bytes of response body kept on a StatusError
+)
+
+// Category is one category document as stored by the backend. Content is
+// opaque to the client; the TUI owns its {"items": [...]} shape.
+// Version is bumped by the backend on every write; 0 means unknown.
+type Category struct {
+	Id      string          `json:"id"`
+	UserId  string          `json:"user_id"`
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
+	Version int             `json:"version,omitempty"`
+}
+
+// Item operations accepted by PatchCategoryItems.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// ItemOp changes one item, addressed by its id, inside a category's items array.
+type ItemOp struct {
+	Op   string          `json:"op"`
+	ID   string          `json:"id"`
+	Item json.RawMessage `json:"item,omitempty"`
+}
+
+// ErrPatchUnsupported is returned by PatchCategoryItems when the backend has
+// no item endpoint; callers fall back to UpdateCategory.
+var ErrPatchUnsupported = errors.New("client: backend does not support item patches")
+
+// API is the subset of the backend the TUI talks to. *Client implements it;
+// tests can substitute a fake.
+type API interface {
+	ListCategories(ctx context.Context, userID string) ([]Category, error)
+	CreateCategory(ctx context.Context, cat Category) (Category, error)
+	UpdateCategory(ctx context.Context, cat Category) (Category, error)
+	PatchCategoryItems(ctx context.Context, catID string, version int, ops []ItemOp) (Category, error)
+	ScrapeCanvas(ctx context.Context, userID string) (json.RawMessage, error)
+}
+
+// StatusError is returned when the backend answers with a non-2xx status.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// IsConflict reports whether err is the backend refusing a write because the
+// category changed since the version the write was based on.
+func IsConflict(err error) bool {
+	code := StatusCode(err)
+	return code == http.StatusConflict || code == http.StatusPreconditionFailed
+}
+
+// StatusCode returns the HTTP status carried by err, or 0 if err is not a StatusError.
+func StatusCode(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode
+	}
+	return 0
+}
+
+type Client struct {
+	baseURL       string
+	http          *http.Client
+	timeout       time.Duration
+	scrapeTimeout time.Duration
+
+	noPatch atomic.Bool // set once the backend has shown it lacks the item endpoint
+
+	// Category ids the backend has acknowledged, and those whose item patch
+	// came back 404. A 404 only means "no item endpoint" once a full write to
+	// the same category succeeds; otherwise the category itself is gone.
+	known      sync.Map
+	patch404ed sync.Map
+}
+
+var _ API = (*Client)(nil)
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying *http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithTimeout bounds every regular request.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithScrapeTimeout bounds ScrapeCanvas, which drives a real browser on the backend.
+func WithScrapeTimeout(d time.Duration) Option {
+	return func(c *Client) { c.scrapeTimeout = d }
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		http:          &http.Client{},
+		timeout:       DefaultTimeout,
+		scrapeTimeout: DefaultScrapeTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) ListCategories(ctx context.Context, userID string) ([]Category, error) {
+	var cats []Category
+	err := c.do(ctx, c.timeout, http.MethodGet, "/categories/"+url.PathEscape(userID), nil, nil, &cats)
+	for _, cat := range cats {
+		c.known.Store(cat.Id, true)
+	}
+	return cats, err
+}
+
+func (c *Client) CreateCategory(ctx context.Context, cat Category) (Category, error) {
+	out := cat
+	err := c.do(ctx, c.timeout, http.MethodPost, "/categories", nil, cat, &out)
+	if err == nil && out.Id != "" {
+		c.known.Store(out.Id, true)
+	}
+	return out, err
+}
+
+// UpdateCategory replaces cat on the backend. When cat.Version is set the write
+// is conditional (If-Match), and a stale version fails with a conflict; see IsConflict.
+// The returned Version is 0 when the backend does not report the new one, since
+// cat.Version is certainly stale by then.
+func (c *Client) UpdateCategory(ctx context.Context, cat Category) (Category, error) {
+	if cat.Id == "" {
+		return cat, errors.New("client: UpdateCategory needs a category id")
+	}
+	header := http.Header{}
+	if cat.Version > 0 {
+		header.Set("If-Match", etag(cat.Version))
+	}
+	out := cat
+	out.Version = 0
+	err := c.do(ctx, c.timeout, http.MethodPut, "/categories/"+url.PathEscape(cat.Id), header, cat, &out)
+	_, patch404ed := c.patch404ed.LoadAndDelete(cat.Id)
+	switch {
+	case err == nil:
+		c.known.Store(cat.Id, true)
+		if patch404ed {
+			c.noPatch.Store(true) // the category exists, so the item route does not
+		}
+	case StatusCode(err) == http.StatusNotFound:
+		c.known.Delete(cat.Id)
+	}
+	return out, err
+}
+
+// PatchCategoryItems applies ops to the category's items in one batch. Like
+// UpdateCategory, a non-zero version makes the write conditional.
+func (c *Client) PatchCategoryItems(ctx context.Context, catID string, version int, ops []ItemOp) (Category, error) {
+	if c.noPatch.Load() {
+		return Category{}, ErrPatchUnsupported
+	}
+	header := http.Header{}
+	if version > 0 {
+		header.Set("If-Match", etag(version))
+	}
+	body := struct {
+		Ops []ItemOp `json:"ops"`
+	}{ops}
+
+	var out Category
+	err := c.do(ctx, c.timeout, http.MethodPatch, "/categories/"+url.PathEscape(catID)+"/items", header, body, &out)
+	switch StatusCode(err) {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		c.noPatch.Store(true)
+		return out, ErrPatchUnsupported
+	case http.StatusNotFound:
+		// Either the route or the category is missing. For a category we know
+		// exists, try the full write; it settles which one it was.
+		if _, ok := c.known.Load(catID); ok {
+			c.patch404ed.Store(catID, true)
+			return out, ErrPatchUnsupported
+		}
+	}
+	if err == nil {
+		c.known.Store(catID, true)
+	}
+	return out, err
+}
+
+// ScrapeCanvas asks the backend to scrape Canvas for userID. The backend stores
+// the result itself; the returned value is the raw "items" array.
+func (c *Client) ScrapeCanvas(ctx context.Context, userID string) (json.RawMessage, error) {
+	var result struct {
+		Items json.RawMessage `json:"items"`
+	}
+	err := c.do(ctx, c.scrapeTimeout, http.MethodPost, "/scrapers/canvas?user_id="+url.QueryEscape(userID), nil, nil, &result)
+	return result.Items, err
+}
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// do runs DoJSON against the backend, bounded by timeout.
+func (c *Client) do(ctx context.Context, timeout time.Duration, method, path string, header http.Header, in, out any) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return DoJSON(ctx, c.http, method, c.baseURL+path, header, in, out)
+}
+
+// DoJSON sends in (if non-nil) as JSON to url and decodes the response into
+// out (if non-nil). A non-2xx answer is a *StatusError; an empty response
+// body leaves out untouched. Other JSON services the TUI talks to use it too.
+func DoJSON(ctx context.Context, hc *http.Client, method, url string, header http.Header, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{
+			Method:     method,
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(b)),
+		}
+	}
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s %s: decoding response: %w", method, url, err)
+	}
+	return nil
+}
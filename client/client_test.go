@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		conflict bool
+	}{
+		{http.StatusNotFound, false},
+		{http.StatusConflict, true},
+		{http.StatusPreconditionFailed, true},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", tt.status)
+		}))
+		c := New(srv.URL)
+		_, err := c.UpdateCategory(context.Background(), Category{Id: "c1", Version: 3})
+		srv.Close()
+
+		var se *StatusError
+		if !errors.As(err, &se) {
+			t.Fatalf("%d: got %v, want a *StatusError", tt.status, err)
+		}
+		if se.StatusCode != tt.status || se.Method != http.MethodPut || !strings.HasSuffix(se.URL, "/categories/c1") || se.Body != "nope" {
+			t.Errorf("%d: got %+v", tt.status, se)
+		}
+		if StatusCode(err) != tt.status {
+			t.Errorf("%d: StatusCode = %d", tt.status, StatusCode(err))
+		}
+		if IsConflict(err) != tt.conflict {
+			t.Errorf("%d: IsConflict = %v, want %v", tt.status, IsConflict(err), tt.conflict)
+		}
+	}
+}
+
+func TestStatusCodeOfOtherErrors(t *testing.T) {
+	if got := StatusCode(errors.New("dial tcp: refused")); got != 0 {
+		t.Errorf("StatusCode = %d, want 0", got)
+	}
+}
+
+// patchServer answers PATCH with patchStatus and PUT with 200 (or 404 for
+// missing ids), counting the requests of each kind.
+type patchServer struct {
+	patchStatus  int
+	missing      map[string]bool
+	patches, put atomic.Int32
+}
+
+func (s *patchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode([]Category{{Id: "c1", Name: "Food", Version: 1}, {Id: "gone", Name: "Old", Version: 1}})
+	case http.MethodPatch:
+		s.patches.Add(1)
+		w.WriteHeader(s.patchStatus)
+	case http.MethodPut:
+		s.put.Add(1)
+		if s.missing[r.URL.Path] {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":"c1","name":"Food"}`))
+	}
+}
+
+func TestPatchFallback(t *testing.T) {
+	ctx := context.Background()
+	ops := []ItemOp{{Op: OpDelete, ID: "x"}}
+
+	tests := []struct {
+		name        string
+		patchStatus int
+		catID       string
+		listFirst   bool // the client has seen the category
+		wantErr     error
+		wantStatus  int
+		noPatch     bool // after a successful PUT fallback
+	}{
+		{"405 means no route", http.StatusMethodNotAllowed, "c1", false, ErrPatchUnsupported, 0, true},
+		{"501 means no route", http.StatusNotImplemented, "c1", false, ErrPatchUnsupported, 0, true},
+		{"404 on an unknown category", http.StatusNotFound, "c1", false, nil, http.StatusNotFound, false},
+		{"404 on a known category", http.StatusNotFound, "c1", true, ErrPatchUnsupported, 0, true},
+		{"404 on a deleted category", http.StatusNotFound, "gone", true, ErrPatchUnsupported, 0, false},
+		{"409 is a conflict", http.StatusConflict, "c1", true, nil, http.StatusConflict, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &patchServer{patchStatus: tt.patchStatus, missing: map[string]bool{"/categories/gone": true}}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+			c := New(ts.URL)
+			if tt.listFirst {
+				if _, err := c.ListCategories(ctx, "u"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := c.PatchCategoryItems(ctx, tt.catID, 1, ops)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantStatus != 0 && StatusCode(err) != tt.wantStatus {
+				t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+			}
+			if errors.Is(err, ErrPatchUnsupported) {
+				c.UpdateCategory(ctx, Category{Id: tt.catID, Version: 1})
+			}
+
+			if got := c.noPatch.Load(); got != tt.noPatch {
+				t.Fatalf("noPatch = %v, want %v", got, tt.noPatch)
+			}
+			before := srv.patches.Load()
+			c.PatchCategoryItems(ctx, tt.catID, 1, ops)
+			if sent := srv.patches.Load() > before; sent == tt.noPatch {
+				t.Errorf("second patch sent = %v with noPatch = %v", sent, tt.noPatch)
+			}
+		})
+	}
+}
+
+func TestUpdateCategoryWithoutVersion(t *testing.T) {
+	var ifMatch string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = r.Header.Get("If-Match")
+		w.Write([]byte(`{"id":"c1"}`))
+	}))
+	defer ts.Close()
+
+	out, err := New(ts.URL).UpdateCategory(context.Background(), Category{Id: "c1", Version: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifMatch != `"4"` {
+		t.Errorf("If-Match = %q", ifMatch)
+	}
+	if out.Version != 0 {
+		t.Errorf("Version = %d, want 0 when the backend reports none", out.Version)
+	}
+}
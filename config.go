@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"tui/client"
+)
+
+// --- CONFIGURATION ---
+// Settings are layered: built-in defaults, then the config file, then
+// DASHBOARD_* environment variables, then command-line flags (applied in main).
+type Config struct {
+	Backend  BackendConfig  `toml:"backend"`
+	Ntfy     NtfyConfig     `toml:"ntfy"`
+	LLM      LLMConfig      `toml:"llm"`
+	Ollama   OllamaConfig   `toml:"ollama"`
+	OpenAI   OpenAIConfig   `toml:"openai"`
+	Orders   OrdersConfig   `toml:"orders"`
+	Products ProductsConfig `toml:"products"`
+	Stores   StoresConfig   `toml:"stores"`
+
+	Delivery []DeliveryOption `toml:"delivery"` // [[delivery]] tables; used when the "Delivery" category is empty
+}
+
+type BackendConfig struct {
+	URL           string        `toml:"url"`
+	Timeout       time.Duration `toml:"timeout"`        // e.g. "10s"
+	ScrapeTimeout time.Duration `toml:"scrape_timeout"` // Canvas scraping drives a browser, so allow longer
+}
+
+type NtfyConfig struct {
+	URL string `toml:"url"` // Full topic URL, e.g. https://ntfy.sh/hackaton
+}
+
+type LLMConfig struct {
+	Provider string `toml:"provider"` // "ollama", "openai" (any OpenAI-compatible server) or "fake"
+}
+
+type OllamaConfig struct {
+	URL   string `toml:"url"`
+	Model string `toml:"model"`
+}
+
+type OpenAIConfig struct {
+	URL    string `toml:"url"` // base URL including the version, e.g. http://localhost:8000/v1
+	Model  string `toml:"model"`
+	APIKey string `toml:"api_key"` // optional; local servers usually ignore it
+}
+
+// minPollInterval is the shortest orders.poll_interval accepted.
+const minPollInterval = time.Second
+
+type OrdersConfig struct {
+	Provider     string        `toml:"provider"` // "mock" or "http"
+	URL          string        `toml:"url"`      // order service base URL for the http provider
+	PollInterval time.Duration `toml:"poll_interval"`
+}
+
+type ProductsConfig struct {
+	File string `toml:"file"` // Open Food Facts dump (.jsonl or .csv, optionally .gz) for barcode lookups
+}
+
+type StoresConfig struct {
+	File string `toml:"file"` // store price lists, used when the "Stores" category is empty
+}
+
+func defaultConfig() Config {
+	return Config{
+		Backend: BackendConfig{
+			URL:           "http://localhost:8080",
+			Timeout:       client.DefaultTimeout,
+			ScrapeTimeout: client.DefaultScrapeTimeout,
+		},
+		// Same topic as the backend cron jobs so every alert lands in one place
+		Ntfy:     NtfyConfig{URL: "https://ntfy.sh/hackaton"},
+		LLM:      LLMConfig{Provider: "ollama"},
+		Ollama:   OllamaConfig{URL: "http://localhost:11434", Model: "gemma3:1b"},
+		Orders:   OrdersConfig{Provider: "mock", PollInterval: 5 * time.Second},
+		Products: ProductsConfig{File: defaultProductsPath()},
+		Stores:   StoresConfig{File: defaultStoresPath()},
+		Delivery: defaultDeliveryOptions(),
+	}
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/dashboard/config.toml (or the OS equivalent).
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dashboard", "config.toml")
+}
+
+// loadConfig builds the config from defaults, the file at path and the environment.
+// Only the default file may be missing; a path given with --config or
+// DASHBOARD_CONFIG must exist, and a malformed file is always an error.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		_, err := toml.DecodeFile(path, &cfg)
+		if errors.Is(err, fs.ErrNotExist) && path == defaultConfigPath() {
+			err = nil
+		}
+		if err != nil {
+			return cfg, fmt.Errorf("reading config %s: %w", path, err)
+		}
+	}
+
+	envOverride(&cfg.Backend.URL, "DASHBOARD_BACKEND_URL")
+	envOverride(&cfg.Ntfy.URL, "DASHBOARD_NTFY_URL")
+	envOverride(&cfg.Ollama.URL, "DASHBOARD_OLLAMA_URL")
+	envOverride(&cfg.Ollama.Model, "DASHBOARD_OLLAMA_MODEL")
+	envOverride(&cfg.LLM.Provider, "DASHBOARD_LLM_PROVIDER")
+	envOverride(&cfg.OpenAI.URL, "DASHBOARD_OPENAI_URL")
+	envOverride(&cfg.OpenAI.Model, "DASHBOARD_OPENAI_MODEL")
+	envOverride(&cfg.OpenAI.APIKey, "DASHBOARD_OPENAI_API_KEY")
+	envOverride(&cfg.Orders.Provider, "DASHBOARD_ORDERS_PROVIDER")
+	envOverride(&cfg.Orders.URL, "DASHBOARD_ORDERS_URL")
+	envOverride(&cfg.Products.File, "DASHBOARD_PRODUCTS_FILE")
+	envOverride(&cfg.Stores.File, "DASHBOARD_STORES_FILE")
+
+	// tea.Tick with a zero or tiny interval would poll the order service in a tight loop
+	if cfg.Orders.PollInterval < minPollInterval {
+		return cfg, fmt.Errorf("reading config %s: orders.poll_interval must be at least %s", path, minPollInterval)
+	}
+	if err := validateDeliveryOptions(cfg.Delivery); err != nil {
+		return cfg, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// newAPIClient builds the backend client described by cfg.
+func newAPIClient(cfg Config) *client.Client {
+	return client.New(cfg.Backend.URL,
+		client.WithTimeout(cfg.Backend.Timeout),
+		client.WithScrapeTimeout(cfg.Backend.ScrapeTimeout),
+	)
+}
+
+func envOverride(dst *string, key string) {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		*dst = v
+	}
+}
+
+func flagOverride(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	if _, err := loadConfig(defaultConfigPath()); err != nil {
+		t.Errorf("missing default config: %v", err)
+	}
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "typo.toml")); err == nil {
+		t.Error("missing --config file was ignored")
+	}
+}
+
+func TestLoadConfigPollInterval(t *testing.T) {
+	tests := []struct {
+		toml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 5 * time.Second, false},
+		{"[orders]\npoll_interval = \"2s\"\n", 2 * time.Second, false},
+		{"[orders]\npoll_interval = \"0s\"\n", 0, true},
+		{"[orders]\npoll_interval = \"-5s\"\n", 0, true},
+		{"[orders]\npoll_interval = \"10ms\"\n", 0, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(path, []byte(tt.toml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.toml, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && cfg.Orders.PollInterval != tt.want {
+			t.Errorf("%q: poll interval = %s, want %s", tt.toml, cfg.Orders.PollInterval, tt.want)
+		}
+	}
+}
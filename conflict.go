@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tui/client"
+)
+
+// --- CONFLICT RESOLUTION ---
+// When a conditional write is refused, the remote copy is fetched and every
+// item that differs between the two copies gets a row the user can resolve.
+// The base (the remote content the local edit started from) decides the
+// defaults: a side that left an item as it was in the base yields to the
+// side that changed or deleted it.
+type conflictRow struct {
+	label    string
+	local    json.RawMessage // nil when the item only exists remotely
+	remote   json.RawMessage // nil when the item only exists locally
+	same     bool
+	useLocal bool
+}
+
+type conflictState struct {
+	name   string
+	remote client.Category
+	rows   []conflictRow
+	diffs  []int // indexes into rows that need a decision
+	cursor int
+}
+
+type conflictFetchedMsg struct {
+	name   string
+	seq    int
+	remote client.Category
+}
+
+func fetchConflictCmd(ctx context.Context, api client.API, token, name string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		cats, err := api.ListCategories(ctx, token)
+		if err != nil {
+			return syncFailedMsg{seq: seq, name: name, err: fmt.Errorf("loading remote %s: %w", name, err)}
+		}
+		// A category deleted remotely resolves against an empty remote copy
+		remote := client.Category{Name: name}
+		for _, c := range cats {
+			if c.Name == name {
+				remote = c
+				break
+			}
+		}
+		return conflictFetchedMsg{name: name, seq: seq, remote: remote}
+	}
+}
+
+func newConflictState(name string, base, local json.RawMessage, remote client.Category) *conflictState {
+	localItems := contentItems(local)
+	remoteItems := contentItems(remote.Content)
+
+	baseByKey := keyedItems(contentItems(base))
+	remoteByKey := keyedItems(remoteItems)
+	// unchanged reports whether item k is exactly as it was in the base
+	unchanged := func(k string, item json.RawMessage) bool {
+		b, ok := baseByKey[k]
+		return ok && item != nil && jsonEqual(b, item)
+	}
+
+	cs := &conflictState{name: name, remote: remote}
+	seen := make(map[string]bool)
+	localKeys := keyedItems(localItems)
+	for _, k := range orderedKeys(localItems) {
+		seen[k] = true
+		row := conflictRow{label: itemLabel(localKeys[k]), local: localKeys[k], remote: remoteByKey[k]}
+		row.same = row.remote != nil && jsonEqual(row.local, row.remote)
+		// Only the remote changed (or deleted) it: take theirs
+		row.useLocal = !unchanged(k, row.local)
+		cs.rows = append(cs.rows, row)
+	}
+	for _, k := range orderedKeys(remoteItems) {
+		if seen[k] {
+			continue
+		}
+		// Only the remote has it: new there, or deleted here when the base had it
+		_, deleted := baseByKey[k]
+		cs.rows = append(cs.rows, conflictRow{label: itemLabel(remoteByKey[k]), remote: remoteByKey[k], useLocal: deleted})
+	}
+	for i, r := range cs.rows {
+		if !r.same {
+			cs.diffs = append(cs.diffs, i)
+		}
+	}
+	return cs
+}
+
+// merged returns the category content built from the user's choices.
+func (cs *conflictState) merged() json.RawMessage {
+	items := []json.RawMessage{}
+	for _, r := range cs.rows {
+		pick := r.remote
+		if r.same || r.useLocal {
+			pick = r.local
+		}
+		if pick != nil {
+			items = append(items, pick)
+		}
+	}
+	content, _ := json.Marshal(map[string]interface{}{"items": items})
+	return content
+}
+
+// --- DISPLAY HELPERS ---
+// Items are handled as raw JSON so one screen serves every category.
+
+func itemLabel(raw json.RawMessage) string {
+	var ident struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(raw, &ident)
+	if ident.Name == "" {
+		return "(unnamed)"
+	}
+	return ident.Name
+}
+
+// summarizeItem renders an item's fields other than id/name as "k=v, ..." in key order.
+func summarizeItem(raw json.RawMessage) string {
+	if raw == nil {
+		return "(deleted)"
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return string(raw)
+	}
+	delete(fields, "id")
+	delete(fields, "name")
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, fields[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// --- MODEL HELPERS ---
+
+func (m *model) openConflict(msg conflictFetchedMsg) {
+	// One conflict at a time; the others keep their writes in flight until their turn
+	if m.conflict != nil && m.conflict.name != msg.name {
+		m.conflictQueue = append(m.conflictQueue, msg)
+		return
+	}
+	local := json.RawMessage(`{"items":[]}`)
+	var base json.RawMessage
+	if w, ok := m.store.pendingFor(msg.name); ok {
+		local, base = w.Content, w.Base
+	}
+	m.conflict = newConflictState(msg.name, base, local, msg.remote)
+	if m.state != stateConflict {
+		m.conflictReturn = m.state
+	}
+	m.state = stateConflict
+	m.statusMsg = fmt.Sprintf("⚠️ %s was changed elsewhere — review the differences.", msg.name)
+}
+
+// nextConflict opens the next queued conflict, if any.
+func (m *model) nextConflict() {
+	if len(m.conflictQueue) == 0 {
+		return
+	}
+	next := m.conflictQueue[0]
+	m.conflictQueue = m.conflictQueue[1:]
+	m.openConflict(next)
+}
+
+// resolveConflict replaces the queued write with content based on the remote
+// version and sends it.
+func (m *model) resolveConflict(content json.RawMessage) tea.Cmd {
+	cs := m.conflict
+	if w, ok := m.store.pendingFor(cs.name); ok {
+		m.store.ack(w.Seq)
+	}
+	m.catIDs[cs.name] = cs.remote.Id // "" when deleted remotely, so the write recreates it
+	m.versions[cs.name] = cs.remote.Version
+	m.applyContent(cs.name, content)
+
+	m.conflict = nil
+	m.state = m.conflictReturn
+	m.cursor = 0
+	m.inFlight[cs.name] = false
+
+	m.store.enqueue(cs.name, m.catIDs[cs.name], cs.remote.Version, cs.remote.Content, content)
+	m.saveStore()
+	m.statusMsg = "Syncing..."
+	cmd := m.sendPending(cs.name)
+	m.nextConflict()
+	return cmd
+}
+
+// discardConflict drops the local write and adopts the remote copy as-is.
+func (m *model) discardConflict() {
+	cs := m.conflict
+	if w, ok := m.store.pendingFor(cs.name); ok {
+		m.store.ack(w.Seq)
+	}
+	m.catIDs[cs.name] = cs.remote.Id
+	m.versions[cs.name] = cs.remote.Version
+	m.applyContent(cs.name, cs.remote.Content)
+	m.saveStore()
+
+	m.conflict = nil
+	m.state = m.conflictReturn
+	m.cursor = 0
+	m.inFlight[cs.name] = false
+	m.statusMsg = fmt.Sprintf("Kept the remote copy of %s.", cs.name)
+	m.nextConflict()
+}
+
+func (m model) updateConflict(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	cs := m.conflict
+	switch msg.String() {
+	case "up", "k":
+		if cs.cursor > 0 {
+			cs.cursor--
+		}
+	case "down", "j":
+		if cs.cursor < len(cs.diffs)-1 {
+			cs.cursor++
+		}
+	case "left", "h":
+		if len(cs.diffs) > 0 {
+			cs.rows[cs.diffs[cs.cursor]].useLocal = true
+		}
+	case "right", "l":
+		if len(cs.diffs) > 0 {
+			cs.rows[cs.diffs[cs.cursor]].useLocal = false
+		}
+	case " ":
+		if len(cs.diffs) > 0 {
+			r := &cs.rows[cs.diffs[cs.cursor]]
+			r.useLocal = !r.useLocal
+		}
+	case "L":
+		for _, i := range cs.diffs {
+			cs.rows[i].useLocal = true
+		}
+	case "R":
+		for _, i := range cs.diffs {
+			cs.rows[i].useLocal = false
+		}
+	case "enter":
+		return m, m.resolveConflict(cs.merged())
+	case "esc":
+		m.discardConflict()
+	}
+	return m, nil
+}
+
+func (m model) viewConflict() string {
+	cs := m.conflict
+	s := titleStyle.Render("⚠️ SYNC CONFLICT — "+cs.name) + "\n"
+	s += "This category changed on the server since your last sync.\nPick which copy of each item to keep.\n\n"
+
+	header := fmt.Sprintf("     %s %s   %s", lipgloss.NewStyle().Width(18).Render("Item"), lipgloss.NewStyle().Width(40).Render("Yours"), "Theirs")
+	s += hintStyle.Render(header) + "\n"
+
+	if len(cs.diffs) == 0 {
+		s += "    Both copies are identical.\n"
+	}
+	for n, i := range cs.diffs {
+		r := cs.rows[i]
+		cursor := "  "
+		if cs.cursor == n {
+			cursor = "▶ "
+		}
+		mine, theirs := "( )", "( )"
+		if r.useLocal {
+			mine = checkStyle.Render("(x)")
+		} else {
+			theirs = checkStyle.Render("(x)")
+		}
+		nameCol := lipgloss.NewStyle().Width(18).Render(r.label)
+		localCol := lipgloss.NewStyle().Width(36).MaxWidth(36).Render(summarizeItem(r.local))
+		remoteCol := lipgloss.NewStyle().MaxWidth(36).Render(summarizeItem(r.remote))
+		line := fmt.Sprintf("  %s %s %s %s   %s %s", cursor, nameCol, mine, localCol, theirs, remoteCol)
+		if cs.cursor == n {
+			s += selStyle.Render(line) + "\n"
+		} else {
+			s += itemStyle.Render(line) + "\n"
+		}
+	}
+	if unchanged := len(cs.rows) - len(cs.diffs); unchanged > 0 {
+		s += hintStyle.Render(fmt.Sprintf("\n  %d unchanged item(s) will be kept.", unchanged)) + "\n"
+	}
+	s += "\n" + hintStyle.Render("[up/down: Navigate • left/right: Yours/Theirs • L/R: All yours/theirs • Enter: Save merge • Esc: Discard yours]")
+	return s
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"tui/client"
+)
+
+func TestConflictDefaults(t *testing.T) {
+	base := json.RawMessage(`{"items":[
+		{"id":"kept","name":"Kept"},
+		{"id":"gone-here","name":"Deleted locally"},
+		{"id":"gone-there","name":"Deleted remotely"},
+		{"id":"theirs","name":"Changed remotely","price":1},
+		{"id":"mine","name":"Changed locally","price":1}]}`)
+	local := json.RawMessage(`{"items":[
+		{"id":"kept","name":"Kept"},
+		{"id":"gone-there","name":"Deleted remotely"},
+		{"id":"theirs","name":"Changed remotely","price":1},
+		{"id":"mine","name":"Changed locally","price":2},
+		{"id":"new-here","name":"New locally"}]}`)
+	remote := client.Category{Content: json.RawMessage(`{"items":[
+		{"id":"kept","name":"Kept"},
+		{"id":"gone-here","name":"Deleted locally"},
+		{"id":"theirs","name":"Changed remotely","price":3},
+		{"id":"mine","name":"Changed locally","price":1},
+		{"id":"new-there","name":"New remotely"}]}`)}
+
+	cs := newConflictState("Food", base, local, remote)
+	got := make(map[string]bool)
+	for _, raw := range contentItems(cs.merged()) {
+		got[itemLabel(raw)] = true
+	}
+	want := map[string]bool{"Kept": true, "Changed remotely": true, "Changed locally": true, "New locally": true, "New remotely": true}
+	if len(got) != len(want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf("merged is missing %q (got %v)", name, got)
+		}
+	}
+	for _, raw := range contentItems(cs.merged()) {
+		var item struct {
+			ID    string  `json:"id"`
+			Price float64 `json:"price"`
+		}
+		json.Unmarshal(raw, &item)
+		if (item.ID == "theirs" && item.Price != 3) || (item.ID == "mine" && item.Price != 2) {
+			t.Errorf("%s has price %v", item.ID, item.Price)
+		}
+	}
+}
+
+func TestConflictsAreQueued(t *testing.T) {
+	m := model{store: &localStore{}, catIDs: map[string]string{}, versions: map[string]int{}, inFlight: map[string]bool{"Food": true, "Subscriptions": true}}
+	m.openConflict(conflictFetchedMsg{name: "Food", remote: client.Category{Id: "f", Name: "Food"}})
+	m.openConflict(conflictFetchedMsg{name: "Subscriptions", remote: client.Category{Id: "s", Name: "Subscriptions"}})
+	if m.conflict.name != "Food" || len(m.conflictQueue) != 1 {
+		t.Fatalf("showing %q with %d queued", m.conflict.name, len(m.conflictQueue))
+	}
+	m.discardConflict()
+	if m.conflict == nil || m.conflict.name != "Subscriptions" {
+		t.Fatalf("second conflict not opened: %+v", m.conflict)
+	}
+	if m.inFlight["Food"] {
+		t.Error("Food write still marked in flight")
+	}
+}
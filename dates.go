@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- DATES ---
+// Date is a calendar day in local time. The zero Date means "TBD".
+// It is stored in the "Jan 02, 2006" layout the backend cron jobs already read.
+type Date struct {
+	time.Time
+}
+
+const (
+	storedDateLayout = "Jan 02, 2006"
+	inputDateLayout  = "2006-01-02"
+)
+
+// Layouts accepted when reading a date, from stored data or typed input.
+var dateLayouts = []string{inputDateLayout, storedDateLayout, "Jan 2, 2006", time.RFC3339}
+
+func dateOf(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)}
+}
+
+func today() Date { return dateOf(time.Now()) }
+
+// parseDate reads s in any accepted layout. "" and "TBD" give the zero Date.
+func parseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "TBD") {
+		return Date{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return dateOf(t), nil
+		}
+	}
+	return Date{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", s)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return "TBD"
+	}
+	return d.Format(storedDateLayout)
+}
+
+// InputString is the value shown in form inputs; TBD is left blank.
+func (d Date) InputString() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(inputDateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts any layout parseDate does; an unreadable date is kept as TBD
+// rather than failing the whole category.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	parsed, err := parseDate(s)
+	if err != nil {
+		parsed = Date{}
+	}
+	*d = parsed
+	return nil
+}
+
+// daysUntil returns the whole days from today to d; TBD dates count as far away.
+func (d Date) daysUntil() int {
+	if d.IsZero() {
+		return 999 // Ignore TBD items
+	}
+	// Round so a DST switch (23h or 25h day) does not shift the count
+	return int(math.Round(d.Sub(today().Time).Hours() / 24))
+}
+
+// addMonths moves d by n months, clamping to the end of shorter months
+// (Jan 31 + 1 month = Feb 28) instead of overflowing like time.AddDate.
+func (d Date) addMonths(n int) Date {
+	return d.monthDay(n, d.Day())
+}
+
+// monthDay returns the given day of the month n months after d, clamped to
+// that month's last day.
+func (d Date) monthDay(n, day int) Date {
+	first := time.Date(d.Year(), d.Month()+time.Month(n), 1, 0, 0, 0, 0, time.Local)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return Date{time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.Local)}
+}
+
+// nextDueDate advances a past due date by the subscription cycle until it is
+// today or later. Each step is taken from the billing day (due's own day when
+// 0), so a Jan 31 subscription bills Feb 28 and then Mar 31, not Mar 28.
+func nextDueDate(due Date, cycle string, day int) Date {
+	return nextDueDateFrom(due, cycle, day, today())
+}
+
+func nextDueDateFrom(due Date, cycle string, day int, now Date) Date {
+	if due.IsZero() || !due.Before(now.Time) {
+		return due
+	}
+	months := int(cycleMonths[cycle])
+	if months <= 0 {
+		months = 1
+	}
+	if day <= 0 {
+		day = due.Day()
+	}
+	next := due
+	for n := months; next.Before(now.Time); n += months {
+		next = due.monthDay(n, day)
+	}
+	return next
+}
+
+type rolloverTickMsg struct{}
+
+// rolloverTickCmd re-checks due dates periodically so a dashboard left open past
+// a payment date still rolls it over.
+func rolloverTickCmd() tea.Cmd {
+	return tea.Tick(time.Hour, func(time.Time) tea.Msg { return rolloverTickMsg{} })
+}
+
+// rolloverSubs advances every passed subscription due date and reports whether any changed.
+func (m *model) rolloverSubs() bool {
+	changed := false
+	for i := range m.subItems {
+		sub := &m.subItems[i]
+		next := nextDueDate(sub.DueDate, sub.Cycle, sub.BillingDay)
+		if !next.Equal(sub.DueDate.Time) {
+			if sub.BillingDay == 0 {
+				sub.BillingDay = sub.DueDate.Day()
+			}
+			sub.DueDate = next
+			changed = true
+		}
+	}
+	return changed
+}
+
+// --- DATE PICKER ---
+
+// pickDate shifts the date in input by the given days/months, starting from
+// today when the input is empty or invalid.
+func pickDate(value string, days, months int) string {
+	d, err := parseDate(value)
+	if err != nil || d.IsZero() {
+		d = today()
+	}
+	d = d.addMonths(months)
+	d = dateOf(d.AddDate(0, 0, days))
+	return d.InputString()
+}
+
+// renderCalendar draws the month around the date in value with that day highlighted.
+func renderCalendar(value string) string {
+	sel, err := parseDate(value)
+	if err != nil || sel.IsZero() {
+		sel = today()
+	}
+	first := time.Date(sel.Year(), sel.Month(), 1, 0, 0, 0, 0, time.Local)
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	s := lipgloss.NewStyle().Width(21).Align(lipgloss.Center).Render(first.Format("January 2006")) + "\n"
+	s += hintStyle.Render(" Mo Tu We Th Fr Sa Su") + "\n"
+
+	offset := (int(first.Weekday()) + 6) % 7 // Monday first
+	s += strings.Repeat("   ", offset)
+	for day := 1; day <= daysInMonth; day++ {
+		cell := fmt.Sprintf("%3d", day)
+		if day == sel.Day() {
+			cell = " " + checkStyle.Reverse(true).Render(fmt.Sprintf("%2d", day))
+		}
+		s += cell
+		if (offset+day)%7 == 0 {
+			s += "\n"
+		}
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func day(s string) Date {
+	d, err := parseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestNextDueDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		due   string
+		cycle string
+		day   int
+		now   string
+		want  string
+	}{
+		{"not yet due", "2026-03-10", "Monthly", 0, "2026-03-01", "2026-03-10"},
+		{"due today", "2026-03-10", "Monthly", 0, "2026-03-10", "2026-03-10"},
+		{"one month", "2026-03-10", "Monthly", 0, "2026-03-11", "2026-04-10"},
+		{"clamped to February", "2026-01-31", "Monthly", 0, "2026-02-01", "2026-02-28"},
+		{"back to the 31st", "2026-01-31", "Monthly", 0, "2026-03-01", "2026-03-31"},
+		{"from a clamped date", "2026-02-28", "Monthly", 31, "2026-03-01", "2026-03-31"},
+		{"30-day month", "2026-01-31", "Monthly", 0, "2026-04-01", "2026-04-30"},
+		{"quarterly", "2025-11-30", "3 Months", 0, "2026-03-01", "2026-05-30"},
+		{"yearly leap day", "2024-02-29", "Yearly", 0, "2026-01-01", "2026-02-28"},
+		{"leap day again", "2026-02-28", "Yearly", 29, "2027-03-01", "2028-02-29"},
+		{"unknown cycle is monthly", "2026-01-15", "", 0, "2026-02-20", "2026-03-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextDueDateFrom(day(tt.due), tt.cycle, tt.day, day(tt.now))
+			if want := day(tt.want); !got.Equal(want.Time) {
+				t.Errorf("got %s, want %s", got.Format(time.DateOnly), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDueDateZero(t *testing.T) {
+	if got := nextDueDate(Date{}, "Monthly", 0); !got.IsZero() {
+		t.Errorf("TBD date became %v", got)
+	}
+}
+
+func TestRolloverKeepsBillingDay(t *testing.T) {
+	m := model{subItems: []SubItem{{Name: "Gym", Cycle: "Monthly", DueDate: today().addMonths(-1).monthDay(0, 1)}}}
+	if !m.rolloverSubs() {
+		t.Fatal("nothing rolled over")
+	}
+	if m.subItems[0].BillingDay != 1 {
+		t.Errorf("BillingDay = %d, want 1", m.subItems[0].BillingDay)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- DELIVERY OPTIONS ---
+// How an order gets home is data: each option has a fee, an optional
+// subtotal above which it is free, and the daily time slots it can be booked
+// in. Options come from the "Delivery" category, else from [[delivery]] in
+// the config file, else the classic $3 delivery and free pickup.
+type DeliveryOption struct {
+	ID       string   `json:"id" toml:"-"`
+	Name     string   `json:"name" toml:"name"`
+	Fee      float64  `json:"fee" toml:"fee"`
+	FreeOver float64  `json:"freeOver,omitempty" toml:"free_over"` // 0: never free
+	Pickup   bool     `json:"pickup,omitempty" toml:"pickup"`      // collected at the store: no fee, no minimum order
+	Slots    []string `json:"slots,omitempty" toml:"slots"`        // daily windows, e.g. "08:00-10:00"; none means as soon as possible
+}
+
+func (o *DeliveryOption) itemID() *string  { return &o.ID }
+func (o *DeliveryOption) itemName() string { return o.Name }
+
+// slotDays is how many days ahead (today included) slots can be booked.
+const slotDays = 3
+
+func defaultDeliveryOptions() []DeliveryOption {
+	return []DeliveryOption{
+		{Name: "Delivery", Fee: 3.00},
+		{Name: "Pick Up", Pickup: true},
+	}
+}
+
+// deliveryOptions are the options offered at checkout.
+func (m model) deliveryOptions() []DeliveryOption {
+	switch {
+	case len(m.deliveryOpts) > 0:
+		return m.deliveryOpts
+	case len(m.cfg.Delivery) > 0:
+		return m.cfg.Delivery
+	}
+	return defaultDeliveryOptions()
+}
+
+// validateDeliveryOptions reports the first option that cannot be offered.
+func validateDeliveryOptions(opts []DeliveryOption) error {
+	seen := make(map[string]bool)
+	for i, o := range opts {
+		name := strings.TrimSpace(o.Name)
+		switch {
+		case name == "":
+			return fmt.Errorf("delivery option %d has no name", i+1)
+		case seen[strings.ToLower(name)]:
+			return fmt.Errorf("delivery option %q is listed twice", name)
+		case o.Fee < 0 || o.FreeOver < 0:
+			return fmt.Errorf("delivery option %q: fee and free-over amount can't be negative", name)
+		}
+		seen[strings.ToLower(name)] = true
+		for _, s := range o.Slots {
+			if _, _, err := parseSlotWindow(s); err != nil {
+				return fmt.Errorf("delivery option %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (o DeliveryOption) label() string {
+	if o.Pickup {
+		return "🏪 " + o.Name
+	}
+	return "🚚 " + o.Name
+}
+
+// feeText is the option's standard fee, as shown in the checkout picker.
+func (o DeliveryOption) feeText() string {
+	switch {
+	case o.Pickup || o.Fee == 0:
+		return "free"
+	case o.FreeOver > 0:
+		return fmt.Sprintf("$%.2f, free over $%.2f", o.Fee, o.FreeOver)
+	}
+	return fmt.Sprintf("$%.2f", o.Fee)
+}
+
+// feeAt is what the option costs for a subtotal bought at s. A store with its
+// own delivery fee charges that instead of the option's fee.
+func (o DeliveryOption) feeAt(s Store, subtotal float64) float64 {
+	if o.Pickup {
+		return 0
+	}
+	if o.FreeOver > 0 && subtotal >= o.FreeOver {
+		return 0
+	}
+	if s.DeliveryFee != nil {
+		return *s.DeliveryFee
+	}
+	return o.Fee
+}
+
+// --- TIME SLOTS ---
+
+// DeliverySlot is a booked delivery or pickup window.
+type DeliverySlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (s DeliverySlot) String() string {
+	return s.Start.Local().Format("Mon Jan 02 15:04") + "–" + s.End.Local().Format("15:04")
+}
+
+// parseSlotWindow reads "HH:MM-HH:MM" as minutes after midnight.
+func parseSlotWindow(w string) (int, int, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(w), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("slot %q: want HH:MM-HH:MM", w)
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if err := errors.Join(err1, err2); err != nil {
+		return 0, 0, fmt.Errorf("slot %q: want HH:MM-HH:MM", w)
+	}
+	a, b := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if b <= a {
+		return 0, 0, fmt.Errorf("slot %q ends before it starts", w)
+	}
+	return a, b, nil
+}
+
+// upcomingSlots lists the option's windows over the next slotDays days that
+// have not started yet, soonest first.
+func (o DeliveryOption) upcomingSlots(now time.Time) []DeliverySlot {
+	var slots []DeliverySlot
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for d := 0; d < slotDays; d++ {
+		day := midnight.AddDate(0, 0, d)
+		for _, w := range o.Slots {
+			a, b, err := parseSlotWindow(w)
+			if err != nil {
+				continue
+			}
+			start := day.Add(time.Duration(a) * time.Minute)
+			if start.After(now) {
+				slots = append(slots, DeliverySlot{Start: start, End: day.Add(time.Duration(b) * time.Minute)})
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Start.Before(slots[j].Start) })
+	return slots
+}
+
+// selectedDelivery is the option picked at checkout.
+func (m model) selectedDelivery() DeliveryOption {
+	opts := m.deliveryOptions()
+	return opts[min(m.buyChoice, len(opts)-1)]
+}
+
+// selectedSlot is the slot picked at checkout, or nil for as soon as possible.
+// It is kept as the slot itself, not a position in the upcoming list, which
+// shifts as soon as the first slot starts.
+func (m model) selectedSlot() *DeliverySlot {
+	return m.slot
+}
+
+// resetSlot picks the soonest slot of the selected option.
+func (m *model) resetSlot() {
+	m.slot = nil
+	if slots := m.selectedDelivery().upcomingSlots(time.Now()); len(slots) > 0 {
+		m.slot = &slots[0]
+	}
+}
+
+// cycleDelivery moves to the next delivery option; stores are repriced, so
+// the store cursor and slot start over.
+func (m *model) cycleDelivery() {
+	m.buyChoice = (m.buyChoice + 1) % len(m.deliveryOptions())
+	m.resetSlot()
+	m.cursor = 0
+}
+
+// moveSlot steps through the upcoming slots of the selected option, from the
+// one picked (or the soonest, once the picked one has started).
+func (m *model) moveSlot(delta int) {
+	slots := m.selectedDelivery().upcomingSlots(time.Now())
+	if len(slots) == 0 {
+		m.slot = nil
+		return
+	}
+	at := 0
+	for i, s := range slots {
+		if m.slot != nil && s.Start.Equal(m.slot.Start) {
+			at = i + delta
+			break
+		}
+	}
+	m.slot = &slots[max(min(at, len(slots)-1), 0)]
+}
+
+// slotStarted reports whether the picked slot can no longer be booked.
+func (m model) slotStarted(now time.Time) bool {
+	return m.slot != nil && !m.slot.Start.After(now)
+}
+
+// reorderOption is the option a reorder rule's delivery mode maps to: the
+// first pickup option for pickups, else the first delivery one.
+func (m model) reorderOption(mode string) DeliveryOption {
+	opts := m.deliveryOptions()
+	for _, o := range opts {
+		if o.Pickup == (mode == deliveryModePickup) {
+			return o
+		}
+	}
+	return opts[0]
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSlotWindow(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end int
+		wantErr    bool
+	}{
+		{"08:00-10:00", 480, 600, false},
+		{" 17:30 - 19:45 ", 1050, 1185, false},
+		{"00:00-23:59", 0, 1439, false},
+		{"10:00-08:00", 0, 0, true},
+		{"10:00-10:00", 0, 0, true},
+		{"8-10", 0, 0, true},
+		{"08:00", 0, 0, true},
+		{"25:00-26:00", 0, 0, true},
+	}
+	for _, tt := range tests {
+		a, b, err := parseSlotWindow(tt.in)
+		if (err != nil) != tt.wantErr || a != tt.start || b != tt.end {
+			t.Errorf("parseSlotWindow(%q) = %d, %d, %v; want %d, %d (error %v)", tt.in, a, b, err, tt.start, tt.end, tt.wantErr)
+		}
+	}
+}
+
+func TestUpcomingSlots(t *testing.T) {
+	loc := time.FixedZone("test", 2*3600)
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 10, day, hour, minute, 0, 0, loc) }
+	o := DeliveryOption{Name: "Evening", Slots: []string{"18:00-20:00", "bad", "08:00-10:00"}}
+
+	got := o.upcomingSlots(at(16, 9, 0))
+	want := []DeliverySlot{
+		{at(16, 18, 0), at(16, 20, 0)}, // today's 08:00 slot has started
+		{at(17, 8, 0), at(17, 10, 0)},
+		{at(17, 18, 0), at(17, 20, 0)},
+		{at(18, 8, 0), at(18, 10, 0)},
+		{at(18, 18, 0), at(18, 20, 0)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d slots: %v", len(got), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("slot %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if slots := (DeliveryOption{Name: "ASAP"}).upcomingSlots(at(16, 9, 0)); len(slots) != 0 {
+		t.Errorf("option without slots offered %v", slots)
+	}
+}
+
+func TestFeeAt(t *testing.T) {
+	own := 1.5
+	tests := []struct {
+		name     string
+		o        DeliveryOption
+		s        Store
+		subtotal float64
+		want     float64
+	}{
+		{"option fee", DeliveryOption{Fee: 3}, Store{}, 10, 3},
+		{"pickup is free", DeliveryOption{Fee: 3, Pickup: true}, Store{DeliveryFee: &own}, 10, 0},
+		{"below free-over", DeliveryOption{Fee: 3, FreeOver: 50}, Store{}, 49.99, 3},
+		{"free over", DeliveryOption{Fee: 3, FreeOver: 50}, Store{}, 50, 0},
+		{"store's own fee", DeliveryOption{Fee: 3}, Store{DeliveryFee: &own}, 10, 1.5},
+		{"free-over beats the store fee", DeliveryOption{Fee: 3, FreeOver: 20}, Store{DeliveryFee: &own}, 25, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.o.feeAt(tt.s, tt.subtotal); got != tt.want {
+			t.Errorf("%s: feeAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDeliveryOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DeliveryOption
+		ok   bool
+	}{
+		{"defaults", defaultDeliveryOptions(), true},
+		{"no name", []DeliveryOption{{Name: " "}}, false},
+		{"listed twice", []DeliveryOption{{Name: "Express"}, {Name: "express"}}, false},
+		{"negative fee", []DeliveryOption{{Name: "Express", Fee: -1}}, false},
+		{"bad slot", []DeliveryOption{{Name: "Express", Slots: []string{"10:00-09:00"}}}, false},
+	}
+	for _, tt := range tests {
+		if err := validateDeliveryOptions(tt.opts); (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
+
+func TestPickedSlotIsKept(t *testing.T) {
+	m := model{deliveryOpts: []DeliveryOption{{Name: "Evening", Slots: []string{"08:00-10:00", "18:00-20:00"}}}}
+	m.resetSlot()
+	first := *m.selectedSlot()
+	m.moveSlot(2)
+	picked := *m.selectedSlot()
+	if !picked.Start.After(first.Start) {
+		t.Fatalf("moving on picked %v, then %v", first, picked)
+	}
+	m.moveSlot(1)
+	m.moveSlot(-1)
+	if got := m.selectedSlot(); !got.Start.Equal(picked.Start) {
+		t.Errorf("moving back and forth picked %v, want %v", got, picked)
+	}
+	if m.slotStarted(first.Start.Add(-time.Minute)) {
+		t.Error("slot counted as started before its start")
+	}
+	if !m.slotStarted(picked.Start) {
+		t.Error("slot not counted as started at its start")
+	}
+
+	// The picked slot has started: moving starts over from the soonest one.
+	m.slot = &DeliverySlot{Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour)}
+	m.moveSlot(1)
+	if got := m.selectedSlot(); !got.Start.After(time.Now()) {
+		t.Errorf("moved to %v, which has started", got)
+	}
+
+	m.deliveryOpts = []DeliveryOption{{Name: "Evening"}, {Name: "Express"}}
+	m.cycleDelivery()
+	if m.selectedSlot() != nil {
+		t.Errorf("option without slots kept %v", m.selectedSlot())
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- EXPIRY ---
+// Part of an item's stock can be split into batches with a best-before date.
+// Batches never add up to more than Amount; whatever they do not cover (e.g.
+// a fresh delivery nobody dated yet) has no known expiry. Stock is used up
+// soonest-expiring batch first.
+
+// expiringDays is how close a best-before date must be to raise an alert.
+const expiringDays = 3
+
+type Batch struct {
+	Qty     float64 `json:"qty"` // in the item's unit
+	Expires Date    `json:"expires"`
+}
+
+// sortBatches orders batches by expiry, soonest first.
+func sortBatches(batches []Batch) {
+	sort.SliceStable(batches, func(i, j int) bool { return batches[i].Expires.Before(batches[j].Expires.Time) })
+}
+
+// datedQty is how much of the stock has a known expiry.
+func (f FoodItem) datedQty() float64 {
+	n := 0.0
+	for _, b := range f.Batches {
+		n += b.Qty
+	}
+	return n
+}
+
+// nextExpiry returns the soonest-expiring batch, if any.
+func (f FoodItem) nextExpiry() (Batch, bool) {
+	if len(f.Batches) == 0 {
+		return Batch{}, false
+	}
+	return f.Batches[0], true
+}
+
+// useStock takes n out of stock, soonest-expiring batches first.
+func (f *FoodItem) useStock(n float64) {
+	f.Amount = max(roundQty(f.Amount-n), 0)
+	for n > 0 && len(f.Batches) > 0 {
+		take := min(n, f.Batches[0].Qty)
+		f.Batches[0].Qty = roundQty(f.Batches[0].Qty - take)
+		n = roundQty(n - take)
+		if f.Batches[0].Qty == 0 {
+			f.Batches = f.Batches[1:]
+		}
+	}
+	f.trimBatches()
+}
+
+// trimBatches keeps the batches within Amount after the stock was lowered
+// by hand, dropping the soonest-expiring ones first.
+func (f *FoodItem) trimBatches() {
+	sortBatches(f.Batches)
+	for extra := roundQty(f.datedQty() - f.Amount); extra > 0 && len(f.Batches) > 0; {
+		take := min(extra, f.Batches[0].Qty)
+		f.Batches[0].Qty = roundQty(f.Batches[0].Qty - take)
+		extra = roundQty(extra - take)
+		if f.Batches[0].Qty == 0 {
+			f.Batches = f.Batches[1:]
+		}
+	}
+	if len(f.Batches) == 0 {
+		f.Batches = nil
+	}
+}
+
+// parseBatches reads the form's batch list: comma separated "qty@YYYY-MM-DD"
+// entries. One entry may leave out the quantity to cover the rest of stock.
+func parseBatches(s string, stock float64) ([]Batch, error) {
+	var batches []Batch
+	rest := -1 // index of the entry without a quantity
+	dated := 0.0
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		qtyText, dateText, ok := strings.Cut(entry, "@")
+		if !ok {
+			qtyText, dateText = "", entry
+		}
+		d, err := parseDate(dateText)
+		if err != nil || d.IsZero() {
+			return nil, fmt.Errorf("invalid expiry %q (use qty@YYYY-MM-DD)", entry)
+		}
+		qty := 0.0
+		if qtyText == "" {
+			if rest >= 0 {
+				return nil, fmt.Errorf("only one expiry may leave out the quantity")
+			}
+			rest = len(batches)
+		} else if qty, err = strconv.ParseFloat(strings.TrimSpace(qtyText), 64); err != nil || math.IsNaN(qty) || qty <= 0 {
+			return nil, fmt.Errorf("invalid quantity in %q", entry)
+		}
+		dated = roundQty(dated + qty)
+		batches = append(batches, Batch{Qty: qty, Expires: d})
+	}
+	if dated > stock {
+		return nil, fmt.Errorf("expiry batches add up to %s but only %s in stock", fmtQty(dated), fmtQty(stock))
+	}
+	if rest >= 0 {
+		if dated == stock {
+			return nil, fmt.Errorf("no stock left for %q", batches[rest].Expires.InputString())
+		}
+		batches[rest].Qty = roundQty(stock - dated)
+	}
+	sortBatches(batches)
+	return batches, nil
+}
+
+// formatBatches is the inverse of parseBatches, used to prefill the form.
+func formatBatches(batches []Batch) string {
+	var parts []string
+	for _, b := range batches {
+		parts = append(parts, fmtQty(b.Qty)+"@"+b.Expires.InputString())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// expiryText describes how long until d: "today", "in 3d" or "EXPIRED".
+func expiryText(d Date) string {
+	switch n := d.daysUntil(); {
+	case n < 0:
+		return "EXPIRED"
+	case n == 0:
+		return "today"
+	default:
+		return fmt.Sprintf("in %dd", n)
+	}
+}
+
+// --- VIEW ---
+
+// expiryTag is the days-to-expiry column of the food table.
+func expiryTag(f FoodItem) string {
+	col := lipgloss.NewStyle().Width(11)
+	b, ok := f.nextExpiry()
+	if !ok {
+		return col.Render("")
+	}
+	color := "#767676"
+	if n := b.Expires.daysUntil(); n < 0 {
+		color = "#FF4C4C"
+	} else if n <= expiringDays {
+		color = "#E1B12C"
+	}
+	return col.Render(lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("⏳ " + expiryText(b.Expires)))
+}
+
+// expiryAlerts are the EXPIRING lines of the ACTION REQUIRED panel, one per
+// batch that is past or close to its date.
+func (m model) expiryAlerts() []string {
+	var lines []string
+	for _, f := range m.foodItems {
+		for _, b := range f.Batches {
+			n := b.Expires.daysUntil()
+			if n > expiringDays {
+				break // sorted, so the rest are later
+			}
+			line := fmt.Sprintf("⏳ EXPIRING: %s %s %s", qtyLabel(b.Qty, f.Unit), f.Name, expiryText(b.Expires))
+			color := "#E1B12C"
+			if n < 0 {
+				line = fmt.Sprintf("⏳ EXPIRED: %s %s", qtyLabel(b.Qty, f.Unit), f.Name)
+				color = "#FF4C4C"
+			}
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBatches(t *testing.T) {
+	tests := []struct {
+		in      string
+		stock   float64
+		want    []Batch
+		wantErr bool
+	}{
+		{"", 5, nil, false},
+		{"2@2026-11-02", 5, []Batch{{2, day("2026-11-02")}}, false},
+		{"2@2026-11-02, 1.5@2026-10-20", 5, []Batch{{1.5, day("2026-10-20")}, {2, day("2026-11-02")}}, false},
+		{"2@2026-11-02, 2026-10-20", 5, []Batch{{3, day("2026-10-20")}, {2, day("2026-11-02")}}, false},
+		{"2026-10-20", 0.5, []Batch{{0.5, day("2026-10-20")}}, false},
+		{"3@2026-11-02, 3@2026-11-03", 5, nil, true}, // more than in stock
+		{"5@2026-11-02, 2026-11-03", 5, nil, true},   // nothing left for the rest
+		{"2026-11-02, 2026-11-03", 5, nil, true},     // two without a quantity
+		{"0@2026-11-02", 5, nil, true},               // zero quantity
+		{"NaN@2026-11-02", 5, nil, true},             // not a number
+		{"two@2026-11-02", 5, nil, true},             // not a number
+		{"2@2026-13-40", 5, nil, true},               // bad date
+		{"2@TBD", 5, nil, true},                      // batches need a date
+	}
+	for _, tt := range tests {
+		got, err := parseBatches(tt.in, tt.stock)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBatches(%q, %v) error = %v, want error %v", tt.in, tt.stock, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBatches(%q, %v) = %v, want %v", tt.in, tt.stock, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBatchesRoundTrip(t *testing.T) {
+	in := []Batch{{1.5, day("2026-10-20")}, {2, day("2026-11-02")}}
+	got, err := parseBatches(formatBatches(in), 3.5)
+	if err != nil || !reflect.DeepEqual(got, in) {
+		t.Errorf("round trip = %v, %v", got, err)
+	}
+}
+
+func TestUseStockTakesSoonestFirst(t *testing.T) {
+	f := FoodItem{Amount: 4, Batches: []Batch{{2, day("2026-10-20")}, {2, day("2026-11-02")}}}
+	f.useStock(3)
+	want := []Batch{{1, day("2026-11-02")}}
+	if f.Amount != 1 || !reflect.DeepEqual(f.Batches, want) {
+		t.Errorf("after using 3: amount %v, batches %v", f.Amount, f.Batches)
+	}
+}
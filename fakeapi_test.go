@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"tui/client"
+)
+
+// fakeAPI is an in-memory client.API. Categories are keyed by id; patchErr,
+// when set, is returned by every PatchCategoryItems call.
+type fakeAPI struct {
+	mu       sync.Mutex
+	cats     map[string]client.Category
+	patchErr error
+	calls    []string // method names, in call order
+}
+
+var _ client.API = (*fakeAPI)(nil)
+
+func newFakeAPI(cats ...client.Category) *fakeAPI {
+	f := &fakeAPI{cats: make(map[string]client.Category)}
+	for _, c := range cats {
+		f.cats[c.Id] = c
+	}
+	return f
+}
+
+func (f *fakeAPI) record(call string) {
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+}
+
+func (f *fakeAPI) ListCategories(ctx context.Context, userID string) ([]client.Category, error) {
+	f.record("ListCategories")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []client.Category
+	for _, c := range f.cats {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (f *fakeAPI) CreateCategory(ctx context.Context, cat client.Category) (client.Category, error) {
+	f.record("CreateCategory")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cat.Id = "cat-" + cat.Name
+	cat.Version = 1
+	f.cats[cat.Id] = cat
+	return cat, nil
+}
+
+func (f *fakeAPI) UpdateCategory(ctx context.Context, cat client.Category) (client.Category, error) {
+	f.record("UpdateCategory")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cur, ok := f.cats[cat.Id]
+	if !ok {
+		return cat, &client.StatusError{Method: http.MethodPut, URL: "/categories/" + cat.Id, StatusCode: http.StatusNotFound}
+	}
+	if cat.Version > 0 && cat.Version != cur.Version {
+		return cat, &client.StatusError{Method: http.MethodPut, URL: "/categories/" + cat.Id, StatusCode: http.StatusPreconditionFailed}
+	}
+	cat.Version = cur.Version + 1
+	f.cats[cat.Id] = cat
+	return cat, nil
+}
+
+func (f *fakeAPI) PatchCategoryItems(ctx context.Context, catID string, version int, ops []client.ItemOp) (client.Category, error) {
+	f.record("PatchCategoryItems")
+	if f.patchErr != nil {
+		return client.Category{}, f.patchErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cur, ok := f.cats[catID]
+	if !ok {
+		return cur, &client.StatusError{Method: http.MethodPatch, URL: "/categories/" + catID + "/items", StatusCode: http.StatusNotFound}
+	}
+	if version > 0 && version != cur.Version {
+		return cur, &client.StatusError{Method: http.MethodPatch, URL: "/categories/" + catID + "/items", StatusCode: http.StatusPreconditionFailed}
+	}
+	items := contentItems(cur.Content)
+	byID := keyedItems(items)
+	order := orderedKeys(items)
+	for _, op := range ops {
+		switch op.Op {
+		case client.OpCreate:
+			order = append(order, op.ID)
+			byID[op.ID] = op.Item
+		case client.OpUpdate:
+			byID[op.ID] = op.Item
+		case client.OpDelete:
+			delete(byID, op.ID)
+		}
+	}
+	next := []json.RawMessage{}
+	for _, id := range order {
+		if item, ok := byID[id]; ok {
+			next = append(next, item)
+		}
+	}
+	cur.Content, _ = json.Marshal(map[string]any{"items": next})
+	cur.Version++
+	f.cats[catID] = cur
+	return cur, nil
+}
+
+func (f *fakeAPI) ScrapeCanvas(ctx context.Context, userID string) (json.RawMessage, error) {
+	f.record("ScrapeCanvas")
+	return json.RawMessage(`[]`), nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// --- FOOD GROUPS ---
+// Food items carry a supermarket aisle and a storage location. The inventory
+// can be shown flat or grouped by either ('g' cycles), and each group folds
+// away with Enter on its header. The cursor then walks rows, not items, so
+// key handlers go through selectedFood to find the item under it.
+
+const (
+	groupNone    = ""
+	groupAisle   = "aisle"
+	groupStorage = "storage"
+)
+
+var foodGroupings = []string{groupNone, groupAisle, groupStorage}
+
+// aisleOrder is the walk through a typical supermarket; the pushed grocery
+// list follows it. Other aisles come after these, alphabetically.
+var aisleOrder = []string{"produce", "bakery", "meat", "fish", "dairy", "frozen", "pantry", "drinks", "household"}
+
+var storageOrder = []string{"fridge", "freezer", "pantry"}
+
+var groupIcons = map[string]string{
+	"produce": "🥬", "bakery": "🥖", "meat": "🥩", "fish": "🐟", "dairy": "🧀", "frozen": "🧊",
+	"pantry": "🥫", "drinks": "🥤", "household": "🧻", "fridge": "❄️", "freezer": "🧊",
+}
+
+// uncategorised is the group of items with no aisle or storage set.
+const uncategorised = "other"
+
+// foodGroup is the normalised group of f under the given grouping.
+func foodGroup(f FoodItem, by string) string {
+	var g string
+	switch by {
+	case groupAisle:
+		g = f.Aisle
+	case groupStorage:
+		g = f.Storage
+	default:
+		return ""
+	}
+	if g = strings.ToLower(strings.TrimSpace(g)); g == "" {
+		return uncategorised
+	}
+	return g
+}
+
+// groupRank sorts known groups in order, then the rest alphabetically, then
+// uncategorised items last.
+func groupRank(g, by string) (int, string) {
+	order := aisleOrder
+	if by == groupStorage {
+		order = storageOrder
+	}
+	for i, o := range order {
+		if g == o {
+			return i, ""
+		}
+	}
+	if g == uncategorised {
+		return len(order) + 1, ""
+	}
+	return len(order), g
+}
+
+func groupLess(a, b, by string) bool {
+	ra, na := groupRank(a, by)
+	rb, nb := groupRank(b, by)
+	if ra != rb {
+		return ra < rb
+	}
+	return na < nb
+}
+
+func groupTitle(g string) string {
+	icon, ok := groupIcons[g]
+	if !ok {
+		icon = "📦"
+	}
+	return icon + " " + strings.ToUpper(g)
+}
+
+// sortByAisle returns items in store-walk order, keeping their order within an aisle.
+func sortByAisle(items []FoodItem) []FoodItem {
+	sorted := append([]FoodItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return groupLess(foodGroup(sorted[i], groupAisle), foodGroup(sorted[j], groupAisle), groupAisle)
+	})
+	return sorted
+}
+
+// foodRow is one line of the inventory: a group header (index -1) or an item.
+type foodRow struct {
+	group string
+	index int // into foodItems
+	count int // items in the group, for headers
+}
+
+// foodRows lays out the inventory under the current grouping, leaving out
+// the items of collapsed groups.
+func (m model) foodRows() []foodRow {
+	var rows []foodRow
+	if m.foodGroupBy == groupNone {
+		for i := range m.foodItems {
+			rows = append(rows, foodRow{index: i})
+		}
+		return rows
+	}
+
+	members := make(map[string][]int)
+	var groups []string
+	for i, f := range m.foodItems {
+		g := foodGroup(f, m.foodGroupBy)
+		if _, ok := members[g]; !ok {
+			groups = append(groups, g)
+		}
+		members[g] = append(members[g], i)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groupLess(groups[i], groups[j], m.foodGroupBy) })
+
+	for _, g := range groups {
+		rows = append(rows, foodRow{group: g, index: -1, count: len(members[g])})
+		if m.collapsed[m.foodGroupBy+":"+g] {
+			continue
+		}
+		for _, i := range members[g] {
+			rows = append(rows, foodRow{group: g, index: i})
+		}
+	}
+	return rows
+}
+
+// selectedFood is the foodItems index under the cursor, or -1 on a header.
+func (m model) selectedFood() int {
+	rows := m.foodRows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return -1
+	}
+	return rows[m.cursor].index
+}
+
+// cycleFoodGrouping switches flat -> by aisle -> by storage, keeping the
+// cursor on the same item where it is still visible.
+func (m *model) cycleFoodGrouping() {
+	sel := m.selectedFood()
+	for i, g := range foodGroupings {
+		if g == m.foodGroupBy {
+			m.foodGroupBy = foodGroupings[(i+1)%len(foodGroupings)]
+			break
+		}
+	}
+	m.cursor = 0
+	for i, r := range m.foodRows() {
+		if sel >= 0 && r.index == sel {
+			m.cursor = i
+			break
+		}
+	}
+	switch m.foodGroupBy {
+	case groupNone:
+		m.statusMsg = "Showing all items"
+	default:
+		m.statusMsg = "Grouped by " + m.foodGroupBy
+	}
+}
+
+// toggleFoodGroup folds or unfolds the group whose header is under the cursor.
+func (m *model) toggleFoodGroup() {
+	rows := m.foodRows()
+	if m.cursor >= len(rows) || rows[m.cursor].index >= 0 {
+		return
+	}
+	key := m.foodGroupBy + ":" + rows[m.cursor].group
+	m.collapsed[key] = !m.collapsed[key]
+}
+
+// clampFoodCursor keeps the cursor on a row after items were removed.
+func (m *model) clampFoodCursor() {
+	if n := len(m.foodRows()); m.cursor >= n {
+		m.cursor = max(n-1, 0)
+	}
+}
+
+// --- VIEW ---
+func (m model) viewFoodHeader(r foodRow, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = "▶ "
+	}
+	arrow := "▾"
+	if m.collapsed[m.foodGroupBy+":"+r.group] {
+		arrow = "▸"
+	}
+	return fmt.Sprintf("  %s%s %s (%d)", cursor, arrow, groupTitle(r.group), r.count)
+}
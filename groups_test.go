@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func groupedModel() model {
+	return model{
+		foodItems: []FoodItem{
+			{ID: "0", Name: "Milk", Aisle: "dairy", Storage: "fridge"},
+			{ID: "1", Name: "Apples", Aisle: "Produce ", Storage: "pantry"},
+			{ID: "2", Name: "Bread", Storage: "pantry"},
+			{ID: "3", Name: "Crisps", Aisle: "snacks"},
+			{ID: "4", Name: "Cheese", Aisle: "dairy", Storage: "fridge"},
+		},
+		collapsed: make(map[string]bool),
+	}
+}
+
+// rowLabels shows rows as group headers ("#dairy") and item names.
+func rowLabels(m model) []string {
+	var out []string
+	for _, r := range m.foodRows() {
+		if r.index < 0 {
+			out = append(out, "#"+r.group)
+			continue
+		}
+		out = append(out, m.foodItems[r.index].Name)
+	}
+	return out
+}
+
+func TestFoodRows(t *testing.T) {
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{groupNone, []string{"Milk", "Apples", "Bread", "Crisps", "Cheese"}},
+		{groupAisle, []string{"#produce", "Apples", "#dairy", "Milk", "Cheese", "#snacks", "Crisps", "#other", "Bread"}},
+		{groupStorage, []string{"#fridge", "Milk", "Cheese", "#pantry", "Apples", "Bread", "#other", "Crisps"}},
+	}
+	for _, tt := range tests {
+		m := groupedModel()
+		m.foodGroupBy = tt.by
+		if got := rowLabels(m); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("grouped by %q: rows = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestSelectedFood(t *testing.T) {
+	m := groupedModel()
+	m.foodGroupBy = groupAisle
+	for cursor, want := range map[int]int{0: -1, 1: 1, 3: 0, 4: 4, 8: 2, 9: -1, -1: -1} {
+		m.cursor = cursor
+		if got := m.selectedFood(); got != want {
+			t.Errorf("cursor %d: selectedFood = %d, want %d", cursor, got, want)
+		}
+	}
+}
+
+func TestCycleFoodGroupingKeepsItem(t *testing.T) {
+	m := groupedModel()
+	m.cursor = 4 // Cheese
+	for _, by := range []string{groupAisle, groupStorage, groupNone} {
+		m.cycleFoodGrouping()
+		if m.foodGroupBy != by {
+			t.Fatalf("grouping = %q, want %q", m.foodGroupBy, by)
+		}
+		if got := m.selectedFood(); got != 4 {
+			t.Errorf("grouped by %q: cursor %d is on %d, want Cheese", by, m.cursor, got)
+		}
+	}
+
+	// From a header there is no item to follow.
+	m.foodGroupBy = groupAisle
+	m.cursor = 2
+	m.cycleFoodGrouping()
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d after leaving a header, want 0", m.cursor)
+	}
+}
+
+func TestToggleFoodGroup(t *testing.T) {
+	m := groupedModel()
+	m.foodGroupBy = groupAisle
+	m.cursor = 2 // #dairy
+	m.toggleFoodGroup()
+
+	want := []string{"#produce", "Apples", "#dairy", "#snacks", "Crisps", "#other", "Bread"}
+	if got := rowLabels(m); !reflect.DeepEqual(got, want) {
+		t.Fatalf("collapsed rows = %v, want %v", got, want)
+	}
+	if r := m.foodRows()[2]; r.count != 2 {
+		t.Errorf("collapsed header counts %d items, want 2", r.count)
+	}
+
+	// Folding is per grouping: dairy items still show by storage.
+	m.foodGroupBy = groupStorage
+	if got := rowLabels(m); len(got) != 8 {
+		t.Errorf("rows by storage = %v", got)
+	}
+
+	// An item inside a folded group cannot keep the cursor.
+	m.foodGroupBy = groupNone
+	m.cursor = 0 // Milk
+	m.cycleFoodGrouping()
+	if m.cursor != 0 || m.selectedFood() != -1 {
+		t.Errorf("cursor = %d on %d, want the first header", m.cursor, m.selectedFood())
+	}
+
+	m.cursor = 1 // an item row: nothing to fold
+	m.toggleFoodGroup()
+	m.cursor = 2
+	m.toggleFoodGroup()
+	if got := rowLabels(m); len(got) != 9 {
+		t.Errorf("unfolded rows = %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- ORDER HISTORY ---
+// Completed orders are kept as the "Orders" category so every terminal (and the
+// backend) shares the same spending ledger.
+type OrderRecord struct {
+	ID          string      `json:"id"` // the provider's order id
+	Lines       []OrderLine `json:"lines"`
+	Store       string      `json:"store,omitempty"`
+	Delivery    string      `json:"delivery"`
+	DeliveryFee float64     `json:"deliveryFee"`
+	Total       float64     `json:"total"`
+	PlacedAt    time.Time   `json:"placedAt"`
+	CompletedAt time.Time   `json:"completedAt"`
+}
+
+func (r *OrderRecord) itemID() *string  { return &r.ID }
+func (r *OrderRecord) itemName() string { return r.PlacedAt.Format(time.RFC3339) }
+
+// recordOrder appends a delivered order to the ledger and syncs it.
+func (m *model) recordOrder(o Order) tea.Cmd {
+	for _, r := range m.orderHistory {
+		if r.ID == o.ID {
+			return nil
+		}
+	}
+	total := o.Total
+	if total == 0 {
+		total = linesTotal(o.Lines) + o.DeliveryFee
+	}
+	m.orderHistory = append(m.orderHistory, OrderRecord{
+		ID:          o.ID,
+		Lines:       o.Lines,
+		Store:       o.Store,
+		Delivery:    o.Delivery,
+		DeliveryFee: o.DeliveryFee,
+		Total:       total,
+		PlacedAt:    o.PlacedAt,
+		CompletedAt: time.Now(),
+	})
+	return m.queueSync("Orders", m.orderHistory)
+}
+
+// monthlySpend sums the orders completed in the month containing t.
+func monthlySpend(history []OrderRecord, t time.Time) float64 {
+	var total float64
+	for _, r := range history {
+		c := r.CompletedAt.Local()
+		if c.Year() == t.Year() && c.Month() == t.Month() {
+			total += r.Total
+		}
+	}
+	return total
+}
+
+// sortedHistory returns the ledger newest first.
+func (m model) sortedHistory() []OrderRecord {
+	out := append([]OrderRecord(nil), m.orderHistory...)
+	sort.Slice(out, func(i, j int) bool { return out[i].CompletedAt.After(out[j].CompletedAt) })
+	return out
+}
+
+// --- VIEW ---
+func (m model) viewOrderHistory() string {
+	s := titleStyle.Render("🧾 ORDER HISTORY") + "\n"
+
+	now := time.Now()
+	spend := lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Bold(true).Render(fmt.Sprintf("$%.2f", monthlySpend(m.orderHistory, now)))
+	s += fmt.Sprintf("💰 Spent in %s: %s", now.Format("January 2006"), spend)
+	// AddDate(0, -1, 0) would normalise Mar 31 to Mar 3; the 1st always lands in the previous month
+	prev := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+	s += hintStyle.Render(fmt.Sprintf("   (%s: $%.2f)", prev.Format("January"), monthlySpend(m.orderHistory, prev))) + "\n\n"
+
+	history := m.sortedHistory()
+	var detail string
+	if m.cursor < len(history) {
+		r := history[m.cursor]
+		for _, l := range r.Lines {
+			detail += fmt.Sprintf("%-8s %-15s = $%.2f\n", qtyLabel(l.Qty, l.Unit), l.Name, l.UnitPrice*l.Qty)
+		}
+		detail += fmt.Sprintf("Delivery: $%.2f\nTotal:    $%.2f", r.DeliveryFee, r.Total)
+		detail = "\n" + boxStyle.Render(detail) + "\n"
+	}
+
+	if len(history) == 0 {
+		s += "    No completed orders yet.\n"
+	}
+	start, end := listWindow(m.cursor, len(history), m.listRows(10+lipgloss.Height(detail)))
+	for i := start; i < end; i++ {
+		r := history[i]
+		cursor := "  "
+		if m.cursor == i {
+			cursor = "▶ "
+		}
+		count := len(r.Lines)
+		line := fmt.Sprintf("  %s %s  %2d line(s)  $%7.2f  %s", cursor, r.CompletedAt.Local().Format("Jan 02, 2006"), count, r.Total, deliveryText(r.Delivery, r.Store))
+		if m.cursor == i {
+			s += selStyle.Render(line) + "\n"
+		} else {
+			s += itemStyle.Render(line) + "\n"
+		}
+	}
+
+	s += pageIndicator(start, end, len(history))
+	s += detail
+
+	s += "\n" + hintStyle.Render("[up/down: Navigate • Esc: Back to Orders]")
+	s += "\n" + m.renderStatus()
+	return s
+}
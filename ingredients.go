@@ -0,0 +1,443 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- RECIPE INGREDIENTS ---
+// A recipe line such as "2 slices whole-wheat bread, toasted" is split into a
+// quantity, a unit and the bare ingredient name, which is then matched against
+// the pantry by name.
+type ingredient struct {
+	Line string
+	Qty  float64 // 0 when the line gives no amount
+	Unit string  // "" for plain counts ("2 eggs")
+	Name string
+}
+
+// recipeUnits maps the spellings models use to one canonical unit.
+var recipeUnits = map[string]string{
+	"g": "g", "gram": "g", "grams": "g", "kg": "kg", "kilogram": "kg", "kilograms": "kg",
+	"ml": "ml", "milliliter": "ml", "milliliters": "ml", "l": "l", "liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"oz": "oz", "ounce": "oz", "ounces": "oz", "lb": "lb", "lbs": "lb", "pound": "lb", "pounds": "lb",
+	"cup": "cup", "cups": "cup", "tbsp": "tbsp", "tablespoon": "tbsp", "tablespoons": "tbsp",
+	"tsp": "tsp", "teaspoon": "tsp", "teaspoons": "tsp",
+	"slice": "slice", "slices": "slice", "clove": "clove", "cloves": "clove", "can": "can", "cans": "can",
+	"piece": "piece", "pieces": "piece", "pinch": "pinch", "handful": "handful", "bunch": "bunch",
+}
+
+// Words that describe an ingredient rather than name it.
+var ingredientFillers = map[string]bool{
+	"of": true, "a": true, "an": true, "large": true, "small": true, "medium": true,
+	"fresh": true, "chopped": true, "diced": true, "sliced": true, "minced": true, "grated": true,
+	"optional": true, "about": true, "some": true,
+}
+
+// Staples the prompt tells the model it may assume ("salt and pepper to taste",
+// "olive oil"); an ingredient made only of these words is never "missing".
+var pantryStapleWords = map[string]bool{
+	"salt": true, "pepper": true, "black": true, "water": true, "oil": true, "olive": true,
+	"cooking": true, "vegetable": true, "and": true, "to": true, "taste": true,
+}
+
+var unicodeFractions = map[rune]float64{'½': 0.5, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 0.25, '¾': 0.75}
+
+func parseIngredient(line string) ingredient {
+	ing := ingredient{Line: line}
+
+	s := strings.ToLower(line)
+	if i := strings.Index(s, "("); i >= 0 {
+		if j := strings.Index(s[i:], ")"); j >= 0 {
+			s = s[:i] + s[i+j+1:]
+		}
+	}
+	if i := strings.IndexAny(s, ",;"); i >= 0 {
+		s = s[:i]
+	}
+
+	words := strings.Fields(s)
+	for len(words) > 0 {
+		w := words[0]
+		if q, ok := parseQty(w); ok {
+			ing.Qty += q
+		} else if u, ok := recipeUnits[strings.TrimSuffix(w, ".")]; ok && ing.Unit == "" && ing.Qty > 0 {
+			ing.Unit = u
+		} else if q, u, ok := splitQtyUnit(w); ok {
+			ing.Qty += q
+			ing.Unit = u
+		} else if !ingredientFillers[w] {
+			break
+		}
+		words = words[1:]
+	}
+	ing.Name = strings.Join(words, " ")
+	return ing
+}
+
+// parseQty reads "2", "1.5", "1/2", "½" or a range like "2-3" (the upper bound).
+func parseQty(w string) (float64, bool) {
+	if r := []rune(w); len(r) == 1 {
+		if f, ok := unicodeFractions[r[0]]; ok {
+			return f, true
+		}
+	}
+	if _, hi, ok := strings.Cut(w, "-"); ok {
+		w = hi
+	}
+	if num, den, ok := strings.Cut(w, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 == nil && err2 == nil && d != 0 {
+			return n / d, true
+		}
+		return 0, false
+	}
+	q, err := strconv.ParseFloat(w, 64)
+	return q, err == nil
+}
+
+// splitQtyUnit reads glued forms such as "200g" or "1.5l".
+func splitQtyUnit(w string) (float64, string, bool) {
+	i := strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	if i <= 0 {
+		return 0, "", false
+	}
+	q, err := strconv.ParseFloat(w[:i], 64)
+	u, ok := recipeUnits[w[i:]]
+	if err != nil || !ok {
+		return 0, "", false
+	}
+	return q, u, true
+}
+
+// --- FUZZY MATCHING ---
+
+// nameTokens lowercases s and splits it into singular words ("Cherry Tomatoes" -> cherry, tomato).
+func nameTokens(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) })
+	for i, w := range words {
+		switch {
+		case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"):
+			words[i] = w[:len(w)-2]
+		case strings.HasSuffix(w, "ies") && len(w) > 4:
+			words[i] = w[:len(w)-3] + "y"
+		case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && len(w) > 3:
+			words[i] = w[:len(w)-1]
+		}
+	}
+	return words
+}
+
+// similarWord allows one typo in words of five letters or more.
+func similarWord(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) < 5 || len(b) < 5 {
+		return false
+	}
+	return editDistance(a, b) <= 1
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// nameScore reports how well a pantry item name matches an ingredient name:
+// every word of the item must appear in the ingredient ("cheese" matches
+// "cheddar cheese"). Longer item names score higher so "cheddar cheese" beats
+// "cheese" when both are stocked. 0 means no match.
+func nameScore(item, ingredientName string) int {
+	itemWords, ingWords := nameTokens(item), nameTokens(ingredientName)
+	if len(itemWords) == 0 {
+		return 0
+	}
+	for _, iw := range itemWords {
+		found := false
+		for _, gw := range ingWords {
+			if similarWord(iw, gw) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return len(itemWords)
+}
+
+func isStaple(name string) bool {
+	words := nameTokens(name)
+	for _, w := range words {
+		if !pantryStapleWords[w] {
+			return false
+		}
+	}
+	return len(words) > 0
+}
+
+// --- PANTRY CHECK ---
+type pantryMatch struct {
+	ingredient
+	FoodIndex int // index into foodItems, -1 when nothing matches
+	Staple    bool
+}
+
+// pantryCheck matches each recipe ingredient line against the pantry.
+func (m model) pantryCheck(lines []string) []pantryMatch {
+	var out []pantryMatch
+	for _, line := range lines {
+		p := pantryMatch{ingredient: parseIngredient(line), FoodIndex: -1}
+		if p.Name == "" {
+			continue
+		}
+		// Staples are assumed, so "salt and pepper" never uses up stocked pepper
+		p.Staple = isStaple(p.Name)
+		if !p.Staple {
+			p.FoodIndex = m.matchFood(p.Name)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// matchFood is the index of the pantry item best matching an ingredient name, or -1.
+func (m model) matchFood(name string) int {
+	best, found := 0, -1
+	for i, f := range m.foodItems {
+		if score := nameScore(f.Name, name); score > best {
+			best, found = score, i
+		}
+	}
+	return found
+}
+
+// recipeIngredients is the pantry check for the recipe on screen, or nil while
+// it is still being written.
+func (m model) recipeIngredients() []pantryMatch {
+	if m.isGenerating || m.generatedRecipe == "" {
+		return nil
+	}
+	return m.pantryCheck(parseRecipe(m.generatedRecipe).Ingredients)
+}
+
+// recipeFinished reports whether the recipe on screen is complete: fully
+// generated, or opened from the recipe book. A cancelled stream leaves a
+// partial one whose last ingredient may be cut off ("2 eg").
+func (m model) recipeFinished() bool {
+	return m.recipeReady || m.recipeOpen != ""
+}
+
+// addMissingToCart puts one of every missing or out-of-stock ingredient in the
+// cart, creating pantry items for ingredients we have never stocked.
+func (m *model) addMissingToCart() tea.Cmd {
+	if !m.recipeFinished() {
+		m.statusMsg = "The recipe is not finished; wait for it or generate it again."
+		return nil
+	}
+	added, created := 0, 0
+	for _, p := range m.recipeIngredients() {
+		if p.Staple {
+			continue
+		}
+		// Match again: an earlier line ("2 eggs" then "1 egg") may have created the item
+		i := m.matchFood(p.Name)
+		switch {
+		case i < 0:
+			m.foodItems = append(m.foodItems, FoodItem{ID: newItemID(), Name: displayName(p.Name), Unit: p.stockUnit(), CartQty: p.buyAmount()})
+			added++
+			created++
+		case m.foodItems[i].Amount <= 0 && m.foodItems[i].CartQty == 0:
+			m.foodItems[i].CartQty = m.foodItems[i].cartStep()
+			added++
+		}
+	}
+	if added == 0 {
+		m.statusMsg = "Nothing missing — you have everything for this recipe ✅"
+		return nil
+	}
+	m.setRecipe(m.generatedRecipe) // the pantry summary under the box changed
+	m.statusMsg = fmt.Sprintf("🛒 Added %d missing ingredient(s) to the cart", added)
+	if created == 0 {
+		m.saveStore() // the cart itself is local-only
+		return nil
+	}
+	m.statusMsg += fmt.Sprintf(" (%d new pantry item(s), set their price with 'e')", created)
+	return m.queueSync("Food", m.foodItems)
+}
+
+// usedAmount is how much of item a recipe line uses up. Plain counts ("2
+// eggs") are taken as given for items counted in pieces, where a measured
+// amount ("2 cloves garlic") uses one; for weighed or measured items the
+// amount is converted to the item's unit. ok is false when it cannot be
+// ("1 cup flour" against flour in kg).
+func (p pantryMatch) usedAmount(item FoodItem) (float64, bool) {
+	if item.unit() == unitPieces {
+		if p.Unit != "" || p.Qty <= 0 {
+			return 1, true
+		}
+		return math.Ceil(p.Qty), true
+	}
+	if p.Unit == "" || p.Qty <= 0 {
+		return 0, false
+	}
+	return convertQty(p.Qty, p.Unit, item.unit())
+}
+
+// stockUnit is the unit for a new pantry item created from this line: the
+// line's own unit when it is one we can stock, else pieces.
+func (p pantryMatch) stockUnit() string {
+	if u, err := parseUnit(p.Unit); err == nil {
+		return u
+	}
+	return unitPieces
+}
+
+// buyAmount is what goes in the cart for a new pantry item: the recipe's
+// amount when it is measured in a stock unit, else one piece.
+func (p pantryMatch) buyAmount() float64 {
+	if p.stockUnit() != unitPieces && p.Qty > 0 {
+		return p.Qty
+	}
+	return 1
+}
+
+// cookRecipe takes the on-screen recipe's ingredients out of stock, re-runs
+// the reorder rules and syncs Food once. A recipe already cooked (this
+// session, or a saved one with a CookedAt) needs a second 'c' to cook again.
+func (m *model) cookRecipe() tea.Cmd {
+	if !m.recipeFinished() {
+		m.statusMsg = "The recipe is not finished; wait for it or generate it again."
+		return nil
+	}
+	saved := -1
+	for i := range m.recipes {
+		if m.recipeOpen != "" && m.recipes[i].ID == m.recipeOpen {
+			saved = i
+			break
+		}
+	}
+	if m.recipeCooked && !m.recipeCookAgain {
+		m.recipeCookAgain = true
+		when := ""
+		if saved >= 0 && !m.recipes[saved].CookedAt.IsZero() {
+			when = " on " + m.recipes[saved].CookedAt.Local().Format("Jan 02")
+		}
+		m.statusMsg = "⚠️ Already cooked" + when + ". Press 'c' again to take the ingredients out of stock again."
+		return nil
+	}
+	m.recipeCookAgain = false
+	matches := m.recipeIngredients()
+	if len(matches) == 0 {
+		m.statusMsg = "No ingredients to take out of stock."
+		return nil
+	}
+
+	var used, unmeasured []string
+	for _, p := range matches {
+		if p.FoodIndex < 0 {
+			continue
+		}
+		item := &m.foodItems[p.FoodIndex]
+		if item.Amount <= 0 {
+			continue
+		}
+		n, ok := p.usedAmount(*item)
+		if !ok {
+			unmeasured = append(unmeasured, item.Name)
+			continue
+		}
+		n = min(n, item.Amount)
+		item.useStock(n)
+		used = append(used, fmt.Sprintf("%s -%s", item.Name, amountText(n, item.Unit)))
+	}
+	m.recipeCooked = true
+	if len(used) == 0 {
+		m.statusMsg = "None of the ingredients were in stock."
+		if len(unmeasured) > 0 {
+			m.statusMsg = "Could not tell how much was used of " + strings.Join(unmeasured, ", ") + "; update it with 'e'."
+		}
+		return nil
+	}
+
+	m.setRecipe(m.generatedRecipe) // the pantry summary under the box changed
+	m.statusMsg = "🍳 Cooked! Used " + strings.Join(used, ", ")
+	if len(unmeasured) > 0 {
+		m.statusMsg += " • update by hand with 'e': " + strings.Join(unmeasured, ", ")
+	}
+	if status := m.applyReorderRules(); status != "" {
+		m.statusMsg += " • " + status
+	}
+	if saved >= 0 {
+		m.recipes[saved].CookedAt = time.Now()
+		return tea.Batch(m.queueSync("Food", m.foodItems), m.queueSync("Recipes", m.recipes))
+	}
+	return m.queueSync("Food", m.foodItems)
+}
+
+// displayName capitalises an ingredient name for a new pantry item.
+func displayName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// viewPantryCheck summarises which recipe ingredients are in stock, or "".
+func (m model) viewPantryCheck() string {
+	matches := m.recipeIngredients()
+	if len(matches) == 0 {
+		return ""
+	}
+	var have, missing, staples []string
+	for _, p := range matches {
+		switch {
+		case p.Staple:
+			staples = append(staples, p.Name)
+		case p.FoodIndex < 0:
+			missing = append(missing, p.Name)
+		case m.foodItems[p.FoodIndex].Amount <= 0:
+			missing = append(missing, m.foodItems[p.FoodIndex].Name+" (out of stock)")
+		default:
+			f := m.foodItems[p.FoodIndex]
+			have = append(have, fmt.Sprintf("%s (%s)", f.Name, amountText(f.Amount, f.Unit)))
+		}
+	}
+
+	width := lipgloss.NewStyle().Width(m.recipeTextWidth() + 6)
+	var lines []string
+	if len(have) > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render("✅ In stock: "+strings.Join(have, ", ")))
+	}
+	if len(missing) > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C")).Render("❌ Missing: "+strings.Join(missing, ", ")))
+	}
+	if len(staples) > 0 {
+		lines = append(lines, hintStyle.Render("🧂 Staples: "+strings.Join(staples, ", ")))
+	}
+	return width.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line string
+		qty  float64
+		unit string
+		name string
+	}{
+		{"2 slices whole-wheat bread, toasted", 2, "slice", "whole-wheat bread"},
+		{"3 eggs", 3, "", "eggs"},
+		{"200g cheddar cheese", 200, "g", "cheddar cheese"},
+		{"1 1/2 cups flour", 1.5, "cup", "flour"},
+		{"½ tsp. salt", 0.5, "tsp", "salt"},
+		{"2-3 large tomatoes (about 300g)", 3, "", "tomatoes"},
+		{"a handful of fresh basil; torn", 0, "", "handful of fresh basil"},
+		{"Salt and pepper to taste", 0, "", "salt and pepper to taste"},
+		{"1.5l milk", 1.5, "l", "milk"},
+		{"cups", 0, "", "cups"},
+	}
+	for _, tt := range tests {
+		got := parseIngredient(tt.line)
+		if got.Qty != tt.qty || got.Unit != tt.unit || got.Name != tt.name {
+			t.Errorf("parseIngredient(%q) = %v %q %q, want %v %q %q", tt.line, got.Qty, got.Unit, got.Name, tt.qty, tt.unit, tt.name)
+		}
+	}
+}
+
+func TestPantryCheckSkipsStaples(t *testing.T) {
+	m := model{foodItems: []FoodItem{{Name: "Pepper", Amount: 1}, {Name: "Cheese", Amount: 1}}}
+	got := m.pantryCheck([]string{"Salt and pepper to taste", "50g cheddar cheese"})
+	if len(got) != 2 {
+		t.Fatalf("got %d matches", len(got))
+	}
+	if !got[0].Staple || got[0].FoodIndex != -1 {
+		t.Errorf("staple matched pantry item %d", got[0].FoodIndex)
+	}
+	if got[1].Staple || got[1].FoodIndex != 1 {
+		t.Errorf("cheddar cheese matched %d (staple %v)", got[1].FoodIndex, got[1].Staple)
+	}
+}
+
+func TestAddMissingToCartOncePerItem(t *testing.T) {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), nil, nil, &localStore{}, "u")
+	m.foodItems = []FoodItem{{ID: "p", Name: "Pepper", Amount: 1}}
+	m.generatedRecipe = "INGREDIENTS:\n- 2 eggs\n- 1 egg, beaten\n- salt and pepper\n- 1 onion"
+	m.recipeReady = true
+	m.addMissingToCart()
+
+	names := make(map[string]int)
+	for _, f := range m.foodItems {
+		names[f.Name]++
+	}
+	if names["Eggs"] != 1 || names["Onion"] != 1 || len(m.foodItems) != 3 {
+		t.Errorf("pantry = %v", m.foodItems)
+	}
+	if m.foodItems[0].CartQty != 0 {
+		t.Errorf("stocked pepper put in the cart")
+	}
+}
+
+func TestAddMissingNeedsFinishedRecipe(t *testing.T) {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), nil, fakeLLMProvider{}, &localStore{}, "u")
+	m.startRecipe("prompt")
+	m.setRecipe("INGREDIENTS:\n- 1 onion\n- 2 eg")
+	m.stopRecipe() // Esc: the partial text stays on screen
+
+	m.addMissingToCart()
+	if len(m.foodItems) != 0 {
+		t.Fatalf("created %v from a cancelled recipe", m.foodItems)
+	}
+
+	m.recipeReady = true
+	m.saveRecipe()
+	m.addMissingToCart()
+	if len(m.foodItems) != 2 {
+		t.Errorf("saved recipe added %v", m.foodItems)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"tui/client"
+)
+
+// --- ITEM IDENTITY ---
+// Every item in a category carries a stable "id". Items saved before ids existed
+// get one derived from their name and position among same-named items, so every
+// terminal assigns the same id to the same legacy item without talking to the others.
+
+func newItemID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func legacyItemID(name string, occurrence int) string {
+	sum := sha1.Sum([]byte(name + "#" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:8])
+}
+
+type identifiable interface {
+	itemID() *string
+	itemName() string
+}
+
+func (f *FoodItem) itemID() *string   { return &f.ID }
+func (f *FoodItem) itemName() string  { return f.Name }
+func (s *SubItem) itemID() *string    { return &s.ID }
+func (s *SubItem) itemName() string   { return s.Name }
+func (a *StudyItem) itemID() *string  { return &a.ID }
+func (a *StudyItem) itemName() string { return a.Name }
+
+// ensureIDs gives every item without an id its legacy id.
+func ensureIDs[T any, P interface {
+	*T
+	identifiable
+}](items []T) {
+	seen := make(map[string]int)
+	for i := range items {
+		p := P(&items[i])
+		if id := p.itemID(); *id == "" {
+			seen[p.itemName()]++
+			*id = legacyItemID(p.itemName(), seen[p.itemName()])
+		}
+	}
+}
+
+// --- RAW ITEMS ---
+
+func contentItems(content json.RawMessage) []json.RawMessage {
+	var wrapper struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	json.Unmarshal(content, &wrapper)
+	return wrapper.Items
+}
+
+// orderedKeys returns each raw item's id, deriving legacy ids the same way ensureIDs does.
+func orderedKeys(items []json.RawMessage) []string {
+	seen := make(map[string]int)
+	keys := make([]string, len(items))
+	for i, raw := range items {
+		var ident struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		json.Unmarshal(raw, &ident)
+		if ident.ID == "" {
+			seen[ident.Name]++
+			ident.ID = legacyItemID(ident.Name, seen[ident.Name])
+		}
+		keys[i] = ident.ID
+	}
+	return keys
+}
+
+func keyedItems(items []json.RawMessage) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage)
+	for i, k := range orderedKeys(items) {
+		out[k] = items[i]
+	}
+	return out
+}
+
+func hasStoredIDs(items []json.RawMessage) bool {
+	for _, raw := range items {
+		var ident struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(raw, &ident)
+		if ident.ID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// diffItems returns the item operations turning base into next. It reports
+// false when base still holds items without a stored id: the backend cannot
+// address those individually, so the whole category must be rewritten once.
+func diffItems(base, next json.RawMessage) ([]client.ItemOp, bool) {
+	baseItems := contentItems(base)
+	if !hasStoredIDs(baseItems) {
+		return nil, false
+	}
+	nextItems := contentItems(next)
+	baseByID := keyedItems(baseItems)
+	nextByID := keyedItems(nextItems)
+
+	var ops []client.ItemOp
+	for i, id := range orderedKeys(nextItems) {
+		old, ok := baseByID[id]
+		switch {
+		case !ok:
+			ops = append(ops, client.ItemOp{Op: client.OpCreate, ID: id, Item: nextItems[i]})
+		case !jsonEqual(old, nextItems[i]):
+			ops = append(ops, client.ItemOp{Op: client.OpUpdate, ID: id, Item: nextItems[i]})
+		}
+	}
+	for _, id := range orderedKeys(baseItems) {
+		if _, ok := nextByID[id]; !ok {
+			ops = append(ops, client.ItemOp{Op: client.OpDelete, ID: id})
+		}
+	}
+	return ops, true
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return string(ca) == string(cb)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"tui/client"
+)
+
+func TestEnsureIDs(t *testing.T) {
+	items := []FoodItem{{Name: "Milk"}, {Name: "Eggs", ID: "kept"}, {Name: "Milk"}}
+	ensureIDs(items)
+
+	if items[1].ID != "kept" {
+		t.Errorf("existing id replaced with %q", items[1].ID)
+	}
+	if items[0].ID != legacyItemID("Milk", 1) || items[2].ID != legacyItemID("Milk", 2) {
+		t.Errorf("legacy ids = %q, %q", items[0].ID, items[2].ID)
+	}
+	if items[0].ID == items[2].ID {
+		t.Error("same-named items share an id")
+	}
+
+	// Every terminal derives the same ids for the same legacy list
+	again := []FoodItem{{Name: "Milk"}, {Name: "Eggs", ID: "kept"}, {Name: "Milk"}}
+	ensureIDs(again)
+	if !reflect.DeepEqual(items, again) {
+		t.Errorf("ids not stable: %v vs %v", items, again)
+	}
+}
+
+func TestDiffItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		next   string
+		wantOK bool
+		want   []client.ItemOp
+	}{
+		{
+			name:   "no changes",
+			base:   `{"items":[{"id":"a","name":"Milk","price":1}]}`,
+			next:   `{"items":[{"name":"Milk","price":1,"id":"a"}]}`,
+			wantOK: true,
+		},
+		{
+			name:   "create, update and delete",
+			base:   `{"items":[{"id":"a","name":"Milk"},{"id":"b","name":"Eggs"}]}`,
+			next:   `{"items":[{"id":"a","name":"Oat milk"},{"id":"c","name":"Flour"}]}`,
+			wantOK: true,
+			want: []client.ItemOp{
+				{Op: client.OpUpdate, ID: "a", Item: json.RawMessage(`{"id":"a","name":"Oat milk"}`)},
+				{Op: client.OpCreate, ID: "c", Item: json.RawMessage(`{"id":"c","name":"Flour"}`)},
+				{Op: client.OpDelete, ID: "b"},
+			},
+		},
+		{
+			name:   "empty base",
+			base:   `{"items":[]}`,
+			next:   `{"items":[{"id":"a","name":"Milk"}]}`,
+			wantOK: true,
+			want:   []client.ItemOp{{Op: client.OpCreate, ID: "a", Item: json.RawMessage(`{"id":"a","name":"Milk"}`)}},
+		},
+		{
+			name: "base without stored ids",
+			base: `{"items":[{"name":"Milk"}]}`,
+			next: `{"items":[{"id":"a","name":"Milk"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, ok := diffItems(json.RawMessage(tt.base), json.RawMessage(tt.next))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(ops, tt.want) {
+				t.Errorf("ops = %+v, want %+v", ops, tt.want)
+			}
+		})
+	}
+}
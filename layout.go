@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- LAYOUT ---
+// Screen sizes come from tea.WindowSizeMsg. Until the first one arrives
+// (width/height 0) everything renders at its natural size.
+
+const (
+	maxRecipeWidth = 76
+	minListRows    = 3
+)
+
+func (m *model) resize(msg tea.WindowSizeMsg) {
+	m.width, m.height = msg.Width, msg.Height
+	m.recipeView.Width = m.recipeTextWidth()
+	m.setRecipe(m.generatedRecipe)
+}
+
+// recipeTextWidth is the wrap width inside the recipe box (which adds 2 border + 4 padding columns).
+func (m model) recipeTextWidth() int {
+	w := maxRecipeWidth
+	if m.width > 0 && m.width-4-6 < w {
+		w = m.width - 4 - 6
+	}
+	if w < 20 {
+		w = 20
+	}
+	return w
+}
+
+// recipeHeight is how many recipe lines fit under the title, box frame,
+// pantry summary, hint and status.
+func (m model) recipeHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	h := m.height - 13
+	if panel := m.viewPantryCheck(); panel != "" {
+		h -= lipgloss.Height(panel) + 1
+	}
+	if h < minListRows {
+		h = minListRows
+	}
+	return h
+}
+
+// setRecipe replaces the recipe text and keeps the viewport in step: it grows
+// with the text up to the screen height, and while the recipe is streaming it
+// follows the newest line unless the user has scrolled up.
+func (m *model) setRecipe(text string) {
+	follow := m.recipeView.AtBottom()
+	m.generatedRecipe = text
+	if m.isGenerating {
+		text += "▌"
+	}
+	content := lipgloss.NewStyle().Width(m.recipeTextWidth()).Render(text)
+	m.recipeView.Height = min(lipgloss.Height(content), m.recipeHeight())
+	m.recipeView.SetContent(content)
+	if m.isGenerating && follow {
+		m.recipeView.GotoBottom()
+	}
+}
+
+func (m model) viewRecipeBox() string {
+	box := boxStyle.Copy().Width(m.recipeTextWidth() + 4)
+	if m.isGenerating {
+		box = box.BorderForeground(lipgloss.Color("#E1B12C"))
+	}
+	s := box.Render(m.recipeView.View())
+	if !m.recipeView.AtTop() || !m.recipeView.AtBottom() {
+		s += "\n" + hintStyle.Render(fmt.Sprintf("  %3.0f%%", m.recipeView.ScrollPercent()*100))
+	}
+	if panel := m.viewPantryCheck(); panel != "" {
+		s += "\n\n" + panel
+	}
+	return s
+}
+
+// listRows is how many list lines fit once reserved lines (title, hints,
+// status, margins) are taken off; 0 means "no limit".
+func (m model) listRows(reserved int) int {
+	if m.height == 0 {
+		return 0
+	}
+	if rows := m.height - reserved; rows > minListRows {
+		return rows
+	}
+	return minListRows
+}
+
+// listWindow returns the [start, end) slice of a total-item list to draw so
+// that the cursor's page is shown.
+func listWindow(cursor, total, rows int) (int, int) {
+	if rows <= 0 || total <= rows {
+		return 0, total
+	}
+	start := cursor / rows * rows
+	end := start + rows
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageIndicator renders "↕ 11-20 of 42" under a list that does not fit, or "".
+func pageIndicator(start, end, total int) string {
+	if start == 0 && end == total {
+		return ""
+	}
+	return hintStyle.Render(fmt.Sprintf("    ↕ %d-%d of %d", start+1, end, total)) + "\n"
+}
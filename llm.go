@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// --- LLM PROVIDERS ---
+// Recipes can come from Ollama, any OpenAI-compatible server (llama.cpp,
+// vLLM, LM Studio...) or a canned fake, chosen by [llm] provider in the config.
+
+// LLMProvider generates a reply to a single-message chat prompt.
+type LLMProvider interface {
+	// Name is shown in the recipe screen title, e.g. "OLLAMA".
+	Name() string
+	// Chat sends prompt and, once the server has accepted it, returns the streamed reply.
+	Chat(ctx context.Context, prompt string) (LLMStream, error)
+}
+
+// LLMStream yields the reply piece by piece. Next returns io.EOF after the
+// last piece, after which Stats reports what the server told us.
+type LLMStream interface {
+	Next() (string, error)
+	Stats() LLMStats
+	Close() error
+}
+
+type LLMStats struct {
+	Tokens       int           // generated tokens, 0 if unknown
+	EvalDuration time.Duration // time spent generating them, 0 if unknown
+}
+
+func newLLMProvider(cfg Config) (LLMProvider, error) {
+	switch cfg.LLM.Provider {
+	case "", "ollama":
+		return &ollamaProvider{url: strings.TrimRight(cfg.Ollama.URL, "/"), model: cfg.Ollama.Model}, nil
+	case "openai":
+		if cfg.OpenAI.URL == "" {
+			return nil, fmt.Errorf("llm: provider \"openai\" needs openai.url")
+		}
+		return &openAIProvider{url: strings.TrimRight(cfg.OpenAI.URL, "/"), model: cfg.OpenAI.Model, apiKey: cfg.OpenAI.APIKey}, nil
+	case "fake":
+		return fakeLLMProvider{}, nil
+	}
+	return nil, fmt.Errorf("llm: unknown provider %q", cfg.LLM.Provider)
+}
+
+// postJSON sends body to url and returns the response once it is a 200.
+func postJSON(ctx context.Context, name, url string, body interface{}, header http.Header) (*http.Response, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s is not running or unreachable: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if len(bytes.TrimSpace(msg)) > 0 {
+			return nil, fmt.Errorf("%s returned status %d: %s", name, resp.StatusCode, bytes.TrimSpace(msg))
+		}
+		return nil, fmt.Errorf("%s returned status %d", name, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
+
+// --- OLLAMA ---
+type OllamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type OllamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+// OllamaChunk is one line of the /api/chat NDJSON stream. The final line has
+// Done set and carries the generation stats.
+type OllamaChunk struct {
+	Message      OllamaMessage `json:"message"`
+	Done         bool          `json:"done"`
+	EvalCount    int           `json:"eval_count"`
+	EvalDuration int64         `json:"eval_duration"` // nanoseconds
+	Error        string        `json:"error"`
+}
+
+type ollamaProvider struct {
+	url   string
+	model string // Make sure you have this model pulled, e.g. 'ollama pull gemma3:1b'
+}
+
+func (p *ollamaProvider) Name() string { return "OLLAMA" }
+
+func (p *ollamaProvider) Chat(ctx context.Context, prompt string) (LLMStream, error) {
+	resp, err := postJSON(ctx, "Ollama", p.url+"/api/chat", OllamaRequest{
+		Model:    p.model,
+		Messages: []OllamaMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ollamaStream{body: resp.Body, scanner: newLineScanner(resp.Body)}, nil
+}
+
+type ollamaStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	done    bool
+	stats   LLMStats
+}
+
+func (s *ollamaStream) Next() (string, error) {
+	if s.done {
+		return "", io.EOF
+	}
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk OllamaChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("failed to read AI response: %v", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("Ollama: %s", chunk.Error)
+		}
+		if chunk.Done {
+			s.done = true
+			s.stats = LLMStats{Tokens: chunk.EvalCount, EvalDuration: time.Duration(chunk.EvalDuration)}
+			if chunk.Message.Content == "" {
+				return "", io.EOF
+			}
+		}
+		if chunk.Message.Content != "" {
+			return chunk.Message.Content, nil
+		}
+	}
+	return "", streamEnded("Ollama", s.scanner.Err())
+}
+
+func (s *ollamaStream) Stats() LLMStats { return s.stats }
+func (s *ollamaStream) Close() error    { return s.body.Close() }
+
+// streamEnded is the error for a body that stopped before the final chunk.
+func streamEnded(name string, err error) error {
+	if err == nil {
+		err = errors.New(name + " closed the stream before finishing")
+	}
+	return fmt.Errorf("failed to read AI response: %v", err)
+}
+
+// --- OPENAI-COMPATIBLE ---
+// POST {url}/chat/completions with "stream": true answers with server-sent
+// events: "data: {json}" lines ending in "data: [DONE]".
+type openAIProvider struct {
+	url    string // base URL including the version, e.g. http://localhost:8000/v1
+	model  string
+	apiKey string
+}
+
+type openAIRequest struct {
+	Model         string          `json:"model"`
+	Messages      []OllamaMessage `json:"messages"`
+	Stream        bool            `json:"stream"`
+	StreamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *openAIProvider) Name() string { return "OPENAI-COMPATIBLE" }
+
+func (p *openAIProvider) Chat(ctx context.Context, prompt string) (LLMStream, error) {
+	req := openAIRequest{
+		Model:    p.model,
+		Messages: []OllamaMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+	}
+	req.StreamOptions.IncludeUsage = true
+
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	resp, err := postJSON(ctx, "LLM server", p.url+"/chat/completions", req, header)
+	if err != nil {
+		return nil, err
+	}
+	return &openAIStream{body: resp.Body, scanner: newLineScanner(resp.Body), start: time.Now()}, nil
+}
+
+type openAIStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	start   time.Time
+	stats   LLMStats
+}
+
+func (s *openAIStream) Next() (string, error) {
+	for s.scanner.Scan() {
+		data, ok := strings.CutPrefix(strings.TrimSpace(s.scanner.Text()), "data:")
+		if !ok {
+			continue // blank separators, comments, "event:" lines
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return "", io.EOF
+		}
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to read AI response: %v", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("LLM server: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			// The server does not report its own timing, so measure the stream instead
+			s.stats = LLMStats{Tokens: chunk.Usage.CompletionTokens, EvalDuration: time.Since(s.start)}
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			return chunk.Choices[0].Delta.Content, nil
+		}
+	}
+	return "", streamEnded("LLM server", s.scanner.Err())
+}
+
+func (s *openAIStream) Stats() LLMStats { return s.stats }
+func (s *openAIStream) Close() error    { return s.body.Close() }
+
+// --- FAKE ---
+// Streams the same recipe every time, word by word, without any network. Useful
+// for demos and for exercising the recipe screens without a model.
+const fakeRecipe = `TITLE: Pantry Omelette
+
+INGREDIENTS:
+- 3 eggs
+- 50g cheese
+- 1 tomato
+- Salt and pepper to taste
+
+INSTRUCTIONS:
+1. Whisk the eggs with a pinch of salt and pepper.
+2. Pour into a hot oiled pan and cook for 2 minutes.
+3. Add the cheese and sliced tomato, fold and serve.`
+
+type fakeLLMProvider struct{}
+
+func (fakeLLMProvider) Name() string { return "FAKE" }
+
+func (fakeLLMProvider) Chat(ctx context.Context, prompt string) (LLMStream, error) {
+	return &fakeLLMStream{ctx: ctx, pieces: strings.SplitAfter(fakeRecipe, " ")}, nil
+}
+
+type fakeLLMStream struct {
+	ctx    context.Context
+	pieces []string
+	next   int
+}
+
+func (s *fakeLLMStream) Next() (string, error) {
+	if err := s.ctx.Err(); err != nil {
+		return "", err
+	}
+	if s.next >= len(s.pieces) {
+		return "", io.EOF
+	}
+	s.next++
+	return s.pieces[s.next-1], nil
+}
+
+func (s *fakeLLMStream) Stats() LLMStats { return LLMStats{Tokens: len(s.pieces)} }
+func (s *fakeLLMStream) Close() error    { return nil }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// llmServer answers every request on path with body and records the
+// Authorization header it got.
+func llmServer(t *testing.T, path, body string, auth *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if auth != nil {
+			*auth = r.Header.Get("Authorization")
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// drain reads s to the end and returns the text and the error that stopped it.
+func drain(s LLMStream) (string, error) {
+	defer s.Close()
+	var b strings.Builder
+	for {
+		piece, err := s.Next()
+		if err != nil {
+			return b.String(), err
+		}
+		b.WriteString(piece)
+	}
+}
+
+func TestOllamaStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      string
+		wantErr   string // "" for a clean io.EOF
+		wantStats LLMStats
+	}{
+		{
+			name: "done with stats",
+			body: `{"message":{"role":"assistant","content":"Pan"},"done":false}` + "\n\n" +
+				`{"message":{"role":"assistant","content":"cakes"},"done":false}` + "\n" +
+				`{"message":{"role":"assistant","content":""},"done":true,"eval_count":42,"eval_duration":2000000000}` + "\n",
+			want:      "Pancakes",
+			wantStats: LLMStats{Tokens: 42, EvalDuration: 2 * time.Second},
+		},
+		{
+			name:      "content on the final chunk",
+			body:      `{"message":{"content":"Soup"},"done":true,"eval_count":1}` + "\n",
+			want:      "Soup",
+			wantStats: LLMStats{Tokens: 1},
+		},
+		{
+			name:    "error chunk",
+			body:    `{"message":{"content":"Pan"}}` + "\n" + `{"error":"model ran out of memory"}` + "\n",
+			want:    "Pan",
+			wantErr: "model ran out of memory",
+		},
+		{
+			name:    "closed early",
+			body:    `{"message":{"content":"Pan"}}` + "\n",
+			want:    "Pan",
+			wantErr: "closed the stream before finishing",
+		},
+		{
+			name:    "not json",
+			body:    "<html>\n",
+			wantErr: "failed to read AI response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := llmServer(t, "/api/chat", tt.body, nil)
+			p := &ollamaProvider{url: srv.URL, model: "test"}
+			s, err := p.Chat(context.Background(), "prompt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := drain(s)
+			checkStreamEnd(t, got, err, tt.want, tt.wantErr)
+			if tt.wantErr == "" && s.Stats() != tt.wantStats {
+				t.Errorf("stats = %+v, want %+v", s.Stats(), tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       string
+		wantErr    string
+		wantTokens int
+	}{
+		{
+			name: "done with usage",
+			body: ": keep-alive\n\n" +
+				`data: {"choices":[{"delta":{"role":"assistant"}}]}` + "\n\n" +
+				"event: message\n" +
+				`data: {"choices":[{"delta":{"content":"Pan"}}]}` + "\n\n" +
+				`data:{"choices":[{"delta":{"content":"cakes"}}]}` + "\n\n" +
+				`data: {"choices":[],"usage":{"completion_tokens":7}}` + "\n\n" +
+				"data: [DONE]\n\n",
+			want:       "Pancakes",
+			wantTokens: 7,
+		},
+		{
+			name:    "error chunk",
+			body:    `data: {"choices":[{"delta":{"content":"Pan"}}]}` + "\n\n" + `data: {"error":{"message":"context length exceeded"}}` + "\n\n",
+			want:    "Pan",
+			wantErr: "context length exceeded",
+		},
+		{
+			name:    "closed early",
+			body:    `data: {"choices":[{"delta":{"content":"Pan"}}]}` + "\n\n",
+			want:    "Pan",
+			wantErr: "closed the stream before finishing",
+		},
+		{
+			name:    "not json",
+			body:    "data: {oops\n\n",
+			wantErr: "failed to read AI response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var auth string
+			srv := llmServer(t, "/v1/chat/completions", tt.body, &auth)
+			p := &openAIProvider{url: srv.URL + "/v1", model: "test", apiKey: "secret"}
+			s, err := p.Chat(context.Background(), "prompt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := drain(s)
+			checkStreamEnd(t, got, err, tt.want, tt.wantErr)
+			if auth != "Bearer secret" {
+				t.Errorf("Authorization = %q", auth)
+			}
+			if tt.wantErr == "" && s.Stats().Tokens != tt.wantTokens {
+				t.Errorf("tokens = %d, want %d", s.Stats().Tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func checkStreamEnd(t *testing.T, got string, err error, want, wantErr string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	switch {
+	case wantErr == "" && !errors.Is(err, io.EOF):
+		t.Errorf("err = %v, want io.EOF", err)
+	case wantErr != "" && (err == nil || errors.Is(err, io.EOF) || !strings.Contains(err.Error(), wantErr)):
+		t.Errorf("err = %v, want one mentioning %q", err, wantErr)
+	}
+}
+
+func TestLLMChatRejectsStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	for _, p := range []LLMProvider{&ollamaProvider{url: srv.URL}, &openAIProvider{url: srv.URL}} {
+		_, err := p.Chat(context.Background(), "prompt")
+		if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "model not found") {
+			t.Errorf("%s: err = %v", p.Name(), err)
+		}
+	}
+}
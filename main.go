@@ -1,11 +1,11 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,11 +14,12 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-)
 
-const baseURL = "http://localhost:8080" // Change to your actual backend URL if needed
+	"tui/client"
+)
 
 // --- APPLICATION STATES ---
 type sessionState int
@@ -34,46 +35,64 @@ const (
 	stateScrapingCanvas // NEW: Scraping loading state
 	stateAddFood
 	stateAddSub
+	stateConflict
+	stateOrders
+	stateOrderHistory
+	stateBudget
+	stateEditBudget
+	stateRecipeBook
+	stateViewRecipe
+	statePantryRecipe
 )
 
 // --- DATA STRUCTURES ---
 type FoodItem struct {
+	ID             string  `json:"id,omitempty"`
 	Name           string  `json:"name"`
-	Price          float64 `json:"price"`
-	Amount         int     `json:"amount"`
-	RenewThreshold int     `json:"renewThreshold"`
-	CartQty        int     `json:"-"`
+	Price          float64 `json:"price"`              // per Unit, or per package when PackSize is set (see units.go)
+	Unit           string  `json:"unit,omitempty"`     // one of stockUnits; "" means pieces
+	PackSize       float64 `json:"packSize,omitempty"` // units per package, 0 = sold per unit
+	Amount         float64 `json:"amount"`
+	RenewThreshold float64 `json:"renewThreshold"`
+	CartQty        float64 `json:"-"`
+	Aisle          string  `json:"aisle,omitempty"`   // supermarket aisle, e.g. "produce" (see groups.go)
+	Storage        string  `json:"storage,omitempty"` // where it is kept: fridge, freezer, pantry...
+	Barcode        string  `json:"barcode,omitempty"` // EAN/UPC, see products.go
+	Batches        []Batch `json:"batches,omitempty"` // dated part of Amount, soonest first (see expiry.go)
+
+	// Reorder rule, evaluated once Amount drops to RenewThreshold (see reorder.go)
+	ReorderQty      float64 `json:"reorderQty,omitempty"`      // 0 means defaultReorderQty steps
+	ReorderAction   string  `json:"reorderAction,omitempty"`   // reorderToCart (default) or reorderToOrder
+	ReorderDelivery string  `json:"reorderDelivery,omitempty"` // deliveryModeDelivery (default) or deliveryModePickup
 }
 
 type SubItem struct {
+	ID      string  `json:"id,omitempty"`
 	Name    string  `json:"name"`
 	Price   float64 `json:"price"`
-	DueDate string  `json:"dueDate"`
+	DueDate Date    `json:"dueDate"`
 	Cycle   string  `json:"cycle"`
+	// BillingDay is the day of month the subscription bills on, kept so
+	// rolling over through a short month does not lose it.
+	BillingDay int `json:"billingDay,omitempty"`
 }
 
 type StudyItem struct {
+	ID      string `json:"id,omitempty"`
 	Name    string `json:"name"`
 	DueDate string `json:"dueDate"`
 }
 
-type CategoryResponse struct {
-	Id      string          `json:"id"`
-	UserId  string          `json:"user_id"`
-	Name    string          `json:"name"`
-	Content json.RawMessage `json:"content"`
-}
-
 // --- MESSAGES ---
-type dataFetchedMsg []CategoryResponse
-type syncSuccessMsg struct{}
-type recipeGeneratedMsg string
-type buyCompleteMsg struct{}
+type dataFetchedMsg []client.Category
 type canvasScrapedMsg []StudyItem // NEW: Message to handle scraped data
 type errMsg struct{ err error }
 
 // --- MAIN MODEL ---
 type model struct {
+	cfg        Config
+	api        client.API
+	ctx        context.Context
 	state      sessionState
 	cursor     int
 	inputs     []textinput.Model
@@ -85,46 +104,82 @@ type model struct {
 
 	subCycleChoices []string
 	subCycleChoice  int
+	reorderChoice   int // index into reorderChoices in the food form
+
+	formErr   string
+	formNote  string      // informational line under the form, e.g. a barcode lookup result
+	fieldErrs fieldErrors // per-input messages from the last save attempt (see validate.go)
 
 	menuChoices []string
 	foodItems   []FoodItem
-	buyChoices  []string
-	subItems    []SubItem
-	studyItems  []StudyItem
+	foodGroupBy string          // groupNone, groupAisle or groupStorage
+	collapsed   map[string]bool // "grouping:group" -> folded in the inventory view
+	buyChoice   int             // index into deliveryOptions at checkout; the cursor picks the store
+	slot        *DeliverySlot   // slot picked at checkout; nil for as soon as possible
+	checkout    *checkoutState  // orders of the checkout being placed
+	stores      []Store         // price lists from the "Stores" category
+	fileStores  []Store         // price lists from the local stores file
 
-	generatedRecipe string
-	isGenerating    bool
-}
+	deliveryOpts []DeliveryOption // from the "Delivery" category; see deliveryOptions
+	subItems     []SubItem
+	studyItems   []StudyItem
 
-// --- OLLAMA STRUCTS ---
-type OllamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type OllamaRequest struct {
-	Model    string          `json:"model"`
-	Messages []OllamaMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
-}
+	orderHistory []OrderRecord
+	budget       BudgetItem
 
-type OllamaResponse struct {
-	Message OllamaMessage `json:"message"`
+	generatedRecipe string
+	isGenerating    bool
+	recipeStream    *recipeStream
+	recipeView      viewport.Model
+	recipeReady     bool   // a finished, unsaved recipe is on screen
+	recipeCooked    bool   // the recipe on screen has already been taken out of stock
+	recipeCookAgain bool   // 'c' was pressed once on a cooked recipe; the next press cooks it again
+	recipeOpen      string // ID of the saved recipe on screen, "" for a generated one
+	recipes         []Recipe
+	recipeServings  int
+	recipeDiet      string
+
+	width, height int
+
+	cancelScrape context.CancelFunc
+
+	store          *localStore
+	offline        bool
+	retryScheduled bool
+	inFlight       map[string]bool // category name -> write currently being sent
+	versions       map[string]int  // category name -> last remote version seen
+
+	conflict       *conflictState
+	conflictReturn sessionState
+	conflictQueue  []conflictFetchedMsg // conflicts waiting for the one on screen
+
+	orderProvider OrderProvider
+	pollScheduled bool
+
+	llm LLMProvider
 }
 
 // --- NEW: PUSH NOTIFICATION COMMAND ---
 type pushNotificationMsg struct{}
 
-func pushGroceryListCmd(token string, items []FoodItem) tea.Cmd {
+func pushGroceryListCmd(cfg Config, items []FoodItem) tea.Cmd {
 	return func() tea.Msg {
 		var list []string
 		var total float64
 
-		// 1. Check if the user has items in their cart
-		for _, item := range items {
+		// 1. Check if the user has items in their cart, listed in store-walk order
+		aisle := ""
+		for _, item := range sortByAisle(items) {
 			if item.CartQty > 0 {
-				cost := item.Price * float64(item.CartQty)
-				list = append(list, fmt.Sprintf("- %dx %s ($%.2f)", item.CartQty, item.Name, cost))
+				if g := foodGroup(item, groupAisle); g != aisle {
+					if aisle != "" {
+						list = append(list, "")
+					}
+					aisle = g
+					list = append(list, groupTitle(g))
+				}
+				cost := item.cost(item.CartQty)
+				list = append(list, fmt.Sprintf("- %s %s ($%.2f)", qtyLabel(item.CartQty, item.Unit), item.Name, cost))
 				total += cost
 			}
 		}
@@ -134,9 +189,9 @@ func pushGroceryListCmd(token string, items []FoodItem) tea.Cmd {
 		// 2. If the cart is empty, send the Low Stock items instead!
 		if len(list) == 0 {
 			title = "⚠️ Low Stock Reminder"
-			for _, item := range items {
+			for _, item := range sortByAisle(items) {
 				if item.RenewThreshold > 0 && item.Amount <= item.RenewThreshold {
-					list = append(list, fmt.Sprintf("- %s (Only %d left)", item.Name, item.Amount))
+					list = append(list, fmt.Sprintf("- %s (Only %s left)", item.Name, amountText(item.Amount, item.Unit)))
 				}
 			}
 		}
@@ -152,9 +207,7 @@ func pushGroceryListCmd(token string, items []FoodItem) tea.Cmd {
 
 		message := title + "\n\n" + strings.Join(list, "\n")
 
-		// Use the EXACT same topic as your backend cron jobs so they all go to the same place!
-
-		req, err := http.NewRequest("POST", "https://ntfy.sh/hackaton", strings.NewReader(message))
+		req, err := http.NewRequest("POST", cfg.Ntfy.URL, strings.NewReader(message))
 		if err != nil {
 			return errMsg{err}
 		}
@@ -163,8 +216,7 @@ func pushGroceryListCmd(token string, items []FoodItem) tea.Cmd {
 		req.Header.Set("Title", "Dashboard Alert")
 		req.Header.Set("Tags", "shopping_bags,iphone")
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil || resp.StatusCode != 200 {
 			return errMsg{fmt.Errorf("Failed to send notification to phone")}
 		}
@@ -174,32 +226,32 @@ func pushGroceryListCmd(token string, items []FoodItem) tea.Cmd {
 	}
 }
 
-// Helper to calculate days until a deadline
+// Helper to calculate days until a deadline given as text (scraped assignments)
 func daysUntil(dateStr string) int {
-	if dateStr == "TBD" {
-		return 999 // Ignore TBD items
-	}
-	layout := "Jan 02, 2006"
-	t, err := time.ParseInLocation(layout, dateStr, time.Local)
+	d, err := parseDate(dateStr)
 	if err != nil {
 		return 999
 	}
-
-	now := time.Now()
-	// Normalize to midnight to avoid hour-truncation math issues
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
-
-	return int(t.Sub(today).Hours() / 24)
+	return d.daysUntil()
 }
 
-func initialModel(token string) model {
-	return model{
-		state:     stateMenu,
-		cursor:    0,
-		editIndex: -1,
-		token:     token,
-		statusMsg: "Fetching data...",
-		catIDs:    make(map[string]string),
+func initialModel(ctx context.Context, cfg Config, api client.API, orders OrderProvider, llm LLMProvider, store *localStore, token string) model {
+	m := model{
+		cfg:           cfg,
+		api:           api,
+		ctx:           ctx,
+		store:         store,
+		orderProvider: orders,
+		llm:           llm,
+		inFlight:      make(map[string]bool),
+		versions:      make(map[string]int),
+		state:         stateMenu,
+		cursor:        0,
+		editIndex:     -1,
+		token:         token,
+		statusMsg:     "Fetching data...",
+		catIDs:        make(map[string]string),
+		collapsed:     make(map[string]bool),
 
 		subCycleChoices: []string{"Monthly", "3 Months", "Yearly"},
 		subCycleChoice:  0,
@@ -208,148 +260,86 @@ func initialModel(token string) model {
 			"🛒 Food (Tracking, Recipes & Shopping)",
 			"💳 Subscriptions (Payments & Dates)",
 			"📚 Academics (Scraped Assignments)",
+			"📦 Orders (Delivery Tracking)",
+			"📊 Budget (Monthly Overview)",
 		},
-		buyChoices: []string{
-			"🚚 Delivery (+$3.00)",
-			"🏪 Pick Up (Free)",
-		},
-		foodItems:  []FoodItem{},
-		subItems:   []SubItem{},
-		studyItems: []StudyItem{},
+		recipeView:     viewport.New(maxRecipeWidth, 20),
+		recipeServings: 2,
+		foodItems:      []FoodItem{},
+		budget:         BudgetItem{ID: budgetItemID, Name: "Monthly budget"},
+		subItems:       []SubItem{},
+		studyItems:     []StudyItem{},
 	}
-}
 
-// --- HTTP COMMANDS ---
-func fetchCategoriesCmd(token string) tea.Cmd {
-	return func() tea.Msg {
-		resp, err := http.Get(baseURL + "/categories/" + token)
-		if err != nil {
-			return errMsg{err}
-		}
-		defer resp.Body.Close()
-
-		var cats []CategoryResponse
-		if err := json.NewDecoder(resp.Body).Decode(&cats); err != nil {
-			return errMsg{err}
-		}
-		return dataFetchedMsg(cats)
+	// Show the last known data instantly; the fetch in Init refreshes it
+	if len(store.Categories) > 0 || len(store.Outbox) > 0 {
+		m.applyCategories(store.Categories)
+		m.statusMsg = "Loaded cached data. Fetching..."
 	}
-}
-
-func syncCategoryCmd(token, name, catId string, items interface{}) tea.Cmd {
-	return func() tea.Msg {
-		contentBytes, _ := json.Marshal(map[string]interface{}{"items": items})
-		payload := CategoryResponse{Id: catId, UserId: token, Name: name, Content: contentBytes}
-		body, _ := json.Marshal(payload)
-
-		var req *http.Request
-		var err error
-
-		if catId == "" {
-			req, err = http.NewRequest("POST", baseURL+"/categories", bytes.NewBuffer(body))
-		} else {
-			req, err = http.NewRequest("PUT", baseURL+"/categories/"+catId, bytes.NewBuffer(body))
-		}
 
-		if err != nil {
-			return errMsg{err}
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-
-		if err != nil || resp.StatusCode >= 400 {
-			msg := "Sync failed"
-			if err != nil {
-				msg = err.Error()
-			}
-			return errMsg{fmt.Errorf(msg)}
-		}
-
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
-		return syncSuccessMsg{}
+	if stores, err := loadStoresFile(cfg.Stores.File); err != nil {
+		m.statusMsg = "⚠️ " + err.Error()
+	} else {
+		m.fileStores = stores
 	}
+	return m
 }
 
-// --- NEW OLLAMA RECIPE COMMAND ---
-func generateRecipeCmd(ingredients []string) tea.Cmd {
+// --- HTTP COMMANDS ---
+func fetchCategoriesCmd(ctx context.Context, api client.API, token string) tea.Cmd {
 	return func() tea.Msg {
-		// 1. Create a highly optimized prompt for the terminal
-		prompt := fmt.Sprintf(
-			"You are an expert chef. Create a short, simple, and tasty recipe using ONLY these ingredients (you can assume I have basic pantry staples like salt, pepper, water, and cooking oil): %s.\n\n"+
-				"Please keep it concise so it fits on a terminal screen. Use this exact plain-text format:\n"+
-				"TITLE: [Name of Dish]\n\n"+
-				"INGREDIENTS:\n- [Item]\n\n"+
-				"INSTRUCTIONS:\n1. [Step 1]\n2. [Step 2]",
-			strings.Join(ingredients, ", "),
-		)
-
-		// 2. Build the exact JSON payload Ollama expects
-		reqBody := OllamaRequest{
-			Model: "gemma3:1b", // Make sure you have this model pulled via 'ollama pull gemma3:1b'
-			Messages: []OllamaMessage{
-				{Role: "user", Content: prompt},
-			},
-			Stream: false, // We want the whole response at once, not streamed
-		}
-
-		bodyBytes, _ := json.Marshal(reqBody)
-
-		// 3. Send the request to local Ollama
-		resp, err := http.Post("http://localhost:11434/api/chat", "application/json", bytes.NewBuffer(bodyBytes))
+		cats, err := api.ListCategories(ctx, token)
 		if err != nil {
-			return errMsg{fmt.Errorf("Ollama is not running or unreachable: %v", err)}
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return errMsg{fmt.Errorf("Ollama returned status %d", resp.StatusCode)}
+			return fetchFailedMsg{err}
 		}
-
-		// 4. Decode the AI's response
-		var result OllamaResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errMsg{fmt.Errorf("failed to read AI response: %v", err)}
-		}
-
-		return recipeGeneratedMsg(strings.TrimSpace(result.Message.Content))
-	}
-}
-
-func processBuyCmd() tea.Cmd {
-	return func() tea.Msg {
-		time.Sleep(1500 * time.Millisecond)
-		return buyCompleteMsg{}
+		return dataFetchedMsg(cats)
 	}
 }
 
 // NEW: Command to scrape canvas
-func scrapeCanvasCmd(token string) tea.Cmd {
+func scrapeCanvasCmd(ctx context.Context, api client.API, token string) tea.Cmd {
 	return func() tea.Msg {
-		// Notice we added ?user_id= to the URL
-		resp, err := http.Post(baseURL+"/scrapers/canvas?user_id="+token, "application/json", nil)
+		raw, err := api.ScrapeCanvas(ctx, token)
 		if err != nil {
 			return errMsg{err}
 		}
-		defer resp.Body.Close()
 
-		var result map[string][]StudyItem
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errMsg{err}
+		var items []StudyItem
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return errMsg{fmt.Errorf("reading scraped assignments: %w", err)}
+			}
 		}
-
-		return canvasScrapedMsg(result["items"])
+		return canvasScrapedMsg(items)
 	}
 }
 
+// Inputs of the food form. The barcode comes first so a scanner can be used
+// as soon as the form opens.
+const (
+	foodBarcode = iota
+	foodName
+	foodPrice
+	foodAmount
+	foodThreshold
+	foodReorderQty
+	foodExpiry
+	foodUnit
+	foodPackSize
+	foodAisle
+	foodStorage
+	foodFieldCount
+)
+
 // --- FORM INIT ---
 func (m *model) initForm(state sessionState, isEdit bool) {
 	m.focusIndex = 0
+	m.formErr = ""
+	m.formNote = ""
+	m.fieldErrs = nil
 
 	if state == stateAddFood {
-		m.inputs = make([]textinput.Model, 4)
+		m.inputs = make([]textinput.Model, foodFieldCount)
 		for i := range m.inputs {
 			t := textinput.New()
 			t.CharLimit = 32
@@ -360,17 +350,39 @@ func (m *model) initForm(state sessionState, isEdit bool) {
 			}
 			m.inputs[i] = t
 		}
-		m.inputs[0].Placeholder = "Food Name"
-		m.inputs[1].Placeholder = "Price per unit"
-		m.inputs[2].Placeholder = "Current Stock Amount"
-		m.inputs[3].Placeholder = "Auto-Renew Threshold (0 = disabled)"
+		m.inputs[foodBarcode].Placeholder = "Barcode (scan or type, Enter to look up)"
+		m.inputs[foodName].Placeholder = "Food Name"
+		m.inputs[foodPrice].Placeholder = "Price (per unit, or per package)"
+		m.inputs[foodAmount].Placeholder = "Current Stock Amount"
+		m.inputs[foodThreshold].Placeholder = "Reorder Threshold (0 = disabled)"
+		m.inputs[foodReorderQty].Placeholder = fmt.Sprintf("Reorder Quantity (default %d)", defaultReorderQty)
+		m.inputs[foodExpiry].Placeholder = "Expiry Dates (e.g. 2@2026-10-20, 2026-11-02)"
+		m.inputs[foodExpiry].CharLimit = 128
+		m.inputs[foodUnit].Placeholder = "Unit (" + strings.Join(stockUnits, ", ") + "; default pcs)"
+		m.inputs[foodPackSize].Placeholder = "Package Size in units (0 = priced per unit)"
+		m.inputs[foodAisle].Placeholder = "Aisle (" + strings.Join(aisleOrder, ", ") + ")"
+		m.inputs[foodStorage].Placeholder = "Storage (" + strings.Join(storageOrder, ", ") + ")"
+		m.reorderChoice = 0
 
 		if isEdit && m.editIndex >= 0 {
 			item := m.foodItems[m.editIndex]
-			m.inputs[0].SetValue(item.Name)
-			m.inputs[1].SetValue(fmt.Sprintf("%.2f", item.Price))
-			m.inputs[2].SetValue(strconv.Itoa(item.Amount))
-			m.inputs[3].SetValue(strconv.Itoa(item.RenewThreshold))
+			m.inputs[foodBarcode].SetValue(item.Barcode)
+			m.inputs[foodName].SetValue(item.Name)
+			m.inputs[foodPrice].SetValue(fmt.Sprintf("%.2f", item.Price))
+			m.inputs[foodAmount].SetValue(fmtQty(item.Amount))
+			m.inputs[foodThreshold].SetValue(fmtQty(item.RenewThreshold))
+			if item.ReorderQty > 0 {
+				m.inputs[foodReorderQty].SetValue(fmtQty(item.ReorderQty))
+			}
+			m.inputs[foodExpiry].SetValue(formatBatches(item.Batches))
+			m.inputs[foodUnit].SetValue(item.unit())
+			if item.PackSize > 0 {
+				m.inputs[foodPackSize].SetValue(fmtQty(item.PackSize))
+			}
+			m.inputs[foodAisle].SetValue(item.Aisle)
+			m.inputs[foodStorage].SetValue(item.Storage)
+			m.setFocus(foodName) // the barcode rarely changes once set
+			m.reorderChoice = reorderChoiceIndex(item)
 		}
 	} else if state == stateAddSub {
 		m.inputs = make([]textinput.Model, 3)
@@ -386,14 +398,14 @@ func (m *model) initForm(state sessionState, isEdit bool) {
 		}
 		m.inputs[0].Placeholder = "Service Name"
 		m.inputs[1].Placeholder = "Price"
-		m.inputs[2].Placeholder = "Payment Date"
+		m.inputs[2].Placeholder = "Payment Date (YYYY-MM-DD)"
 		m.subCycleChoice = 0
 
 		if isEdit && m.editIndex >= 0 {
 			item := m.subItems[m.editIndex]
 			m.inputs[0].SetValue(item.Name)
 			m.inputs[1].SetValue(fmt.Sprintf("%.2f", item.Price))
-			m.inputs[2].SetValue(item.DueDate)
+			m.inputs[2].SetValue(item.DueDate.InputString())
 			for i, c := range m.subCycleChoices {
 				if c == item.Cycle {
 					m.subCycleChoice = i
@@ -401,80 +413,225 @@ func (m *model) initForm(state sessionState, isEdit bool) {
 				}
 			}
 		}
+	} else if state == stateEditBudget {
+		t := textinput.New()
+		t.CharLimit = 12
+		t.Focus()
+		t.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+		t.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+		t.Placeholder = "Monthly budget (0 = none)"
+		if m.budget.Limit > 0 {
+			t.SetValue(fmt.Sprintf("%.2f", m.budget.Limit))
+		}
+		m.inputs = []textinput.Model{t}
+	} else if state == statePantryRecipe {
+		servings := textinput.New()
+		servings.CharLimit = 2
+		servings.Prompt = "Servings: "
+		servings.SetValue(strconv.Itoa(m.recipeServings))
+		servings.Focus()
+		servings.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+		servings.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+
+		diet := textinput.New()
+		diet.CharLimit = 64
+		diet.Prompt = "Diet: "
+		diet.Placeholder = "e.g. vegetarian, no nuts (optional)"
+		diet.SetValue(m.recipeDiet)
+		m.inputs = []textinput.Model{servings, diet}
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(textinput.Blink, fetchCategoriesCmd(m.token))
+	return tea.Batch(textinput.Blink, fetchCategoriesCmd(m.ctx, m.api, m.token), m.pollOrders(), rolloverTickCmd())
 }
 
 // Update --- UPDATE ---
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
+	case tea.WindowSizeMsg:
+		m.resize(msg)
+		return m, nil
+
 	case dataFetchedMsg:
-		m.statusMsg = "Data loaded successfully."
-		for _, cat := range msg {
-			m.catIDs[cat.Name] = cat.Id
-			var wrapper map[string]json.RawMessage
-			json.Unmarshal(cat.Content, &wrapper)
-
-			switch cat.Name {
-			case "Food":
-				json.Unmarshal(wrapper["items"], &m.foodItems)
-			case "Subscriptions":
-				json.Unmarshal(wrapper["items"], &m.subItems)
-			case "Academics":
-				json.Unmarshal(wrapper["items"], &m.studyItems)
+		if m.offline {
+			m.statusMsg = "Back online ✓"
+			m.offline = false
+		} else if m.statusMsg == "Fetching data..." || m.statusMsg == "Loaded cached data. Fetching..." {
+			m.statusMsg = "Data loaded successfully."
+		}
+		m.applyCategories(msg)
+		m.store.Categories = msg
+		m.saveStore()
+		cmds := []tea.Cmd{m.replayOutbox()}
+		if m.rolloverSubs() {
+			cmds = append(cmds, m.queueSync("Subscriptions", m.subItems))
+		}
+		return m, tea.Batch(cmds...)
+
+	case rolloverTickMsg:
+		if m.rolloverSubs() {
+			return m, tea.Batch(m.queueSync("Subscriptions", m.subItems), rolloverTickCmd())
+		}
+		return m, rolloverTickCmd()
+
+	case fetchFailedMsg:
+		if isOfflineErr(msg.err) {
+			if !m.offline {
+				m.statusMsg = "Backend unreachable — working offline"
 			}
+			return m, m.goOffline()
 		}
+		m.statusMsg = "Error: " + msg.err.Error()
 		return m, nil
 
+	case conflictFetchedMsg:
+		m.openConflict(msg)
+		return m, nil
+
+	case retryOnlineMsg:
+		m.retryScheduled = false
+		return m, fetchCategoriesCmd(m.ctx, m.api, m.token)
+
 	case syncSuccessMsg:
+		m.inFlight[msg.name] = false
+		m.store.ack(msg.seq)
+		m.store.rebase(msg.name, msg.version, msg.content)
+		m.store.recordRemote(msg.name, msg.catID, msg.version, msg.content)
+		m.versions[msg.name] = msg.version
+		if msg.catID != "" {
+			m.catIDs[msg.name] = msg.catID
+		}
+		m.saveStore()
 		if m.statusMsg == "Syncing..." || m.statusMsg == "Syncing deletion..." || m.statusMsg == "Syncing Canvas data..." {
 			m.statusMsg = "Saved securely to database ✓"
 		}
-		return m, fetchCategoriesCmd(m.token)
-
-	case recipeGeneratedMsg:
-		m.isGenerating = false
-		m.generatedRecipe = string(msg)
+		// A newer edit may have been queued while this one was in flight
+		return m, tea.Batch(m.sendPending(msg.name), fetchCategoriesCmd(m.ctx, m.api, m.token))
+
+	case syncFailedMsg:
+		if client.IsConflict(msg.err) {
+			// Leave the write queued and in flight so nothing resends it until resolved
+			m.statusMsg = "⚠️ " + msg.name + " was changed elsewhere. Loading remote copy..."
+			return m, fetchConflictCmd(m.ctx, m.api, m.token, msg.name, msg.seq)
+		}
+		m.inFlight[msg.name] = false
+		if isOfflineErr(msg.err) {
+			m.statusMsg = "Offline — change saved locally, will sync later"
+			return m, m.goOffline()
+		}
+		// The backend rejected the write; replaying it would fail the same way
+		m.store.ack(msg.seq)
+		m.saveStore()
+		m.statusMsg = "Error: " + msg.err.Error()
 		return m, nil
 
-	case buyCompleteMsg:
-		for i := range m.foodItems {
-			if m.foodItems[i].CartQty > 0 {
-				m.foodItems[i].Amount += m.foodItems[i].CartQty
-				m.foodItems[i].CartQty = 0
-			}
+	case recipeStartedMsg:
+		if msg.stream != m.recipeStream {
+			return m, nil // a stream the user already cancelled
 		}
-		m.state = stateFood
+		m.setRecipe("")
+		return m, msg.stream.next()
+
+	case recipeChunkMsg:
+		if msg.stream != m.recipeStream {
+			return m, nil
+		}
+		m.setRecipe(m.generatedRecipe + msg.text)
+		return m, msg.stream.next()
+
+	case recipeDoneMsg:
+		if msg.stream != m.recipeStream {
+			return m, nil
+		}
+		m.stopRecipe()
+		m.setRecipe(strings.TrimSpace(m.generatedRecipe))
+		m.recipeReady = m.generatedRecipe != ""
+		m.statusMsg = msg.summary()
+		return m, nil
+
+	case orderPlacedMsg:
+		// The items are on their way; stock is added once the order is delivered.
+		// Only the ordered lines leave the cart: a confirmed reorder may not cover it all.
+		m.takeFromCart(msg.req.Lines)
+		m.state = stateOrders
 		m.cursor = 0
-		m.statusMsg = "Order placed! Stock updated in database 🚚"
-		return m, syncCategoryCmd(m.token, "Food", m.catIDs["Food"], m.foodItems)
+		m.statusMsg = "Order placed! Track it here 🚚"
+		return m, m.trackOrder(msg.order)
+
+	case orderUpdatedMsg:
+		return m, m.trackOrder(msg.order)
+
+	case checkoutPlacedMsg:
+		return m, m.checkoutPlaced(msg)
+
+	case productLookupMsg:
+		// Ignore answers for a form that was closed or a barcode that was changed meanwhile
+		if m.state != stateAddFood || strings.TrimSpace(m.inputs[foodBarcode].Value()) != msg.code {
+			return m, nil
+		}
+		m.formNote = ""
+		if errors.Is(msg.err, errProductNotFound) {
+			m.formErr = "No product found for barcode " + msg.code + "; fill in the details by hand."
+		} else if msg.err != nil {
+			m.formErr = msg.err.Error()
+		} else {
+			m.prefillProduct(msg.product)
+		}
+		return m, nil
+
+	case reorderFailedMsg:
+		m.store.Reorders = append([]OrderRequest{msg.req}, m.store.Reorders...)
+		m.saveStore()
+		m.statusMsg = "Error: " + msg.err.Error()
+		return m, nil
+
+	case orderPollMsg:
+		return m, m.pollOrders()
+
+	case orderStatusFailedMsg:
+		// Keep polling; the provider may just be briefly unreachable
+		m.statusMsg = "Error: " + msg.err.Error()
+		return m, m.schedulePoll()
 
 	// NEW: Handle Canvas scraping completion
 	// Replace your old case canvasScrapedMsg with this:
 	case canvasScrapedMsg:
+		m.stopScrape()
 		m.studyItems = msg
+		ensureIDs(m.studyItems)
 		m.state = stateStudy
 		m.cursor = 0
 		m.statusMsg = "Canvas sync complete! ✅"
 
 		// Instead of syncing to the database (the backend did that for us),
 		// we just fetch categories to grab the new Database ID!
-		return m, fetchCategoriesCmd(m.token)
+		return m, fetchCategoriesCmd(m.ctx, m.api, m.token)
 
 	case pushNotificationMsg:
 		m.statusMsg = "📲 Sent to your phone!"
 		return m, nil
 
-	case errMsg:
-		m.isGenerating = false
+	case recipeFailedMsg:
+		if msg.stream != m.recipeStream {
+			return m, nil
+		}
+		m.stopRecipe()
 		m.statusMsg = "Error: " + msg.err.Error()
-		if m.state == stateFoodRecipe {
-			m.generatedRecipe = "Server Error: " + msg.err.Error()
+		m.setRecipe("Server Error: " + msg.err.Error())
+		return m, nil
+
+	case errMsg:
+		if m.state == stateScrapingCanvas {
+			m.stopScrape()
+			m.state = stateStudy
 		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.statusMsg = "Cancelled."
+			return m, nil
+		}
+		m.statusMsg = "Error: " + msg.err.Error()
 		return m, nil
 
 	case tea.KeyMsg:
@@ -482,17 +639,75 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		// Esc aborts an in-flight Canvas scrape
+		if m.state == stateScrapingCanvas && msg.String() == "esc" {
+			m.stopScrape()
+			m.goBack()
+			m.statusMsg = "Canvas sync cancelled."
+			return m, nil
+		}
+
+		// Esc stops a recipe that is still streaming, keeping what arrived so far
+		if m.state == stateFoodRecipe && m.isGenerating && msg.String() == "esc" {
+			m.stopRecipe()
+			m.setRecipe(m.generatedRecipe) // drop the streaming cursor
+			m.statusMsg = "Recipe generation cancelled."
+			return m, nil
+		}
+
+		if m.state == stateConflict {
+			return m.updateConflict(msg)
+		}
+
+		// On recipe screens keys other than back/quit/save scroll the recipe
+		if m.state == stateFoodRecipe || m.state == stateViewRecipe {
+			switch msg.String() {
+			case "esc", "backspace", "q":
+			case "s":
+				if m.state == stateFoodRecipe {
+					return m, m.saveRecipe()
+				}
+			case "m":
+				return m, m.addMissingToCart()
+			case "c":
+				return m, m.cookRecipe()
+			default:
+				var cmd tea.Cmd
+				m.recipeView, cmd = m.recipeView.Update(msg)
+				return m, cmd
+			}
+		}
+
 		// Block normal inputs if we are in a loading state
 		if m.state == stateProcessingBuy || m.state == stateScrapingCanvas {
 			return m, nil
 		}
 
-		if m.state == stateAddFood || m.state == stateAddSub {
+		if m.isForm() {
 			switch msg.String() {
 
 			case "esc":
 				m.goBack()
 				return m, nil
+			case "[", "]", "{", "}":
+				// Date picker: [ ] move a day, { } move a month
+				if m.state == stateAddSub && m.focusIndex == 2 {
+					days, months := 0, 0
+					switch msg.String() {
+					case "[":
+						days = -1
+					case "]":
+						days = 1
+					case "{":
+						months = -1
+					case "}":
+						months = 1
+					}
+					m.inputs[2].SetValue(pickDate(m.inputs[2].Value(), days, months))
+					m.inputs[2].CursorEnd()
+					m.fieldEdited(2)
+					return m, nil
+				}
 			case "left", "right":
 				if m.state == stateAddSub && m.focusIndex == 3 {
 					if msg.String() == "left" && m.subCycleChoice > 0 {
@@ -502,13 +717,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					return m, nil
 				}
+				if m.state == stateAddFood && m.focusIndex == len(m.inputs) {
+					if msg.String() == "left" && m.reorderChoice > 0 {
+						m.reorderChoice--
+					} else if msg.String() == "right" && m.reorderChoice < len(reorderChoices)-1 {
+						m.reorderChoice++
+					}
+					return m, nil
+				}
 			case "tab", "shift+tab", "enter", "up", "down":
 				s := msg.String()
-				totalFields := 4
+				if s == "enter" && m.state == stateAddFood && m.focusIndex == foodBarcode {
+					return m, m.lookupBarcode()
+				}
+				totalFields := len(m.inputs)
+				if m.state == stateAddSub || m.state == stateAddFood {
+					totalFields++ // the cycle / reorder radio row
+				}
 
 				if s == "enter" && m.focusIndex == totalFields-1 {
-					cmd := m.saveForm()
-					m.goBack()
+					cmd, err := m.saveForm()
+					if err != nil {
+						m.formErr = err.Error()
+						return m, nil
+					}
+					if m.isForm() { // saving may have moved to another screen already
+						m.goBack()
+					}
 					return m, cmd
 				}
 				if s == "up" || s == "shift+tab" {
@@ -521,20 +756,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else if m.focusIndex < 0 {
 					m.focusIndex = totalFields - 1
 				}
-
-				cmds := make([]tea.Cmd, len(m.inputs))
-				for i := 0; i < len(m.inputs); i++ {
-					if i == m.focusIndex {
-						cmds[i] = m.inputs[i].Focus()
-						m.inputs[i].PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
-						m.inputs[i].TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
-					} else {
-						m.inputs[i].Blur()
-						m.inputs[i].PromptStyle = lipgloss.NewStyle()
-						m.inputs[i].TextStyle = lipgloss.NewStyle()
-					}
+				return m, m.setFocus(m.focusIndex)
+			}
+			if i := m.focusIndex; i < len(m.inputs) {
+				before := m.inputs[i].Value()
+				cmd := m.updateInputs(msg)
+				if m.inputs[i].Value() != before {
+					m.fieldEdited(i)
 				}
-				return m, tea.Batch(cmds...)
+				return m, cmd
 			}
 			return m, m.updateInputs(msg)
 		}
@@ -555,7 +785,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				limit = len(m.menuChoices) - 1
 			}
 			if m.state == stateFood {
-				limit = len(m.foodItems) - 1
+				limit = len(m.foodRows()) - 1
 			}
 			if m.state == stateSubs {
 				limit = len(m.subItems) - 1
@@ -564,7 +794,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				limit = len(m.studyItems) - 1
 			}
 			if m.state == stateFoodBuy {
-				limit = len(m.buyChoices) - 1
+				limit = len(m.checkoutOptions()) - 1
+			}
+			if m.state == stateOrders {
+				limit = len(m.store.Orders) - 1
+			}
+			if m.state == stateOrderHistory {
+				limit = len(m.orderHistory) - 1
+			}
+			if m.state == stateRecipeBook {
+				limit = len(m.recipes) - 1
 			}
 			if m.cursor < limit {
 				m.cursor++
@@ -582,9 +821,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "e":
-			if m.state == stateFood && len(m.foodItems) > 0 {
+			if i := m.selectedFood(); m.state == stateFood && i >= 0 {
 				m.state = stateAddFood
-				m.editIndex = m.cursor
+				m.editIndex = i
 				m.initForm(stateAddFood, true)
 			} else if m.state == stateSubs && len(m.subItems) > 0 {
 				m.state = stateAddSub
@@ -594,14 +833,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "d":
 			m.statusMsg = "Syncing deletion..."
-			if m.state == stateFood && len(m.foodItems) > 0 {
-				m.foodItems = append(m.foodItems[:m.cursor], m.foodItems[m.cursor+1:]...)
-				if m.cursor >= len(m.foodItems) && len(m.foodItems) > 0 {
-					m.cursor = len(m.foodItems) - 1
-				} else if len(m.foodItems) == 0 {
-					m.cursor = 0
-				}
-				return m, syncCategoryCmd(m.token, "Food", m.catIDs["Food"], m.foodItems)
+			if i := m.selectedFood(); m.state == stateFood && i >= 0 {
+				m.foodItems = append(m.foodItems[:i], m.foodItems[i+1:]...)
+				m.clampFoodCursor()
+				return m, m.queueSync("Food", m.foodItems)
 			} else if m.state == stateSubs && len(m.subItems) > 0 {
 				m.subItems = append(m.subItems[:m.cursor], m.subItems[m.cursor+1:]...)
 				if m.cursor >= len(m.subItems) && len(m.subItems) > 0 {
@@ -609,50 +844,82 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else if len(m.subItems) == 0 {
 					m.cursor = 0
 				}
-				return m, syncCategoryCmd(m.token, "Subscriptions", m.catIDs["Subscriptions"], m.subItems)
+				return m, m.queueSync("Subscriptions", m.subItems)
+			} else if m.state == stateRecipeBook && len(m.recipes) > 0 {
+				m.recipes = append(m.recipes[:m.cursor], m.recipes[m.cursor+1:]...)
+				if m.cursor >= len(m.recipes) && len(m.recipes) > 0 {
+					m.cursor = len(m.recipes) - 1
+				} else if len(m.recipes) == 0 {
+					m.cursor = 0
+				}
+				return m, m.queueSync("Recipes", m.recipes)
 			}
 
 		// Replace your old case "s" with this:
 		case "s":
 			if m.state == stateStudy {
 				m.state = stateScrapingCanvas
-				return m, scrapeCanvasCmd(m.token) // Pass the token here
+				ctx, cancel := context.WithCancel(m.ctx)
+				m.cancelScrape = cancel
+				return m, scrapeCanvasCmd(ctx, m.api, m.token)
 			}
 
 		// ADD TO CART / REDUCE FROM CART
 		case "right", "+":
-			if m.state == stateFood && len(m.foodItems) > 0 {
-				m.foodItems[m.cursor].CartQty++
+			if m.state == stateFoodBuy {
+				m.moveSlot(1)
+			}
+			if i := m.selectedFood(); m.state == stateFood && i >= 0 {
+				item := &m.foodItems[i]
+				item.CartQty = roundQty(item.CartQty + item.cartStep())
+				m.saveStore()
 			}
 		case "left", "-":
-			if m.state == stateFood && len(m.foodItems) > 0 {
-				if m.foodItems[m.cursor].CartQty > 0 {
-					m.foodItems[m.cursor].CartQty--
-				}
+			if m.state == stateFoodBuy {
+				m.moveSlot(-1)
+			}
+			if i := m.selectedFood(); m.state == stateFood && i >= 0 {
+				item := &m.foodItems[i]
+				item.CartQty = max(roundQty(item.CartQty-item.cartStep()), 0)
+				m.saveStore()
 			}
 		case " ":
-			if m.state == stateFood && len(m.foodItems) > 0 {
-				if m.foodItems[m.cursor].CartQty == 0 {
-					m.foodItems[m.cursor].CartQty = 1
+			if i := m.selectedFood(); m.state == stateFood && i >= 0 {
+				if m.foodItems[i].CartQty == 0 {
+					m.foodItems[i].CartQty = m.foodItems[i].cartStep()
 				} else {
-					m.foodItems[m.cursor].CartQty = 0
+					m.foodItems[i].CartQty = 0
 				}
+				m.saveStore()
+			}
+
+		case "g":
+			if m.state == stateFood {
+				m.cycleFoodGrouping()
+			}
+
+		case "tab":
+			// Delivery and pickup reprice every store, so the order of stores may change
+			if m.state == stateFoodBuy {
+				m.cycleDelivery()
 			}
 
 		case "p":
 			// Allow pushing from either the Inventory screen or the Checkout screen
 			if m.state == stateFood || m.state == stateFoodBuy {
 				m.statusMsg = "⏳ Sending to phone..."
-				return m, pushGroceryListCmd(m.token, m.foodItems)
+				return m, pushGroceryListCmd(m.cfg, m.foodItems)
 			}
 
 		case "r":
+			if m.state == stateOrders {
+				m.statusMsg = "Refreshing orders..."
+				return m, m.refreshOrders()
+			}
 			if m.state == stateFood {
 				m.state = stateFoodRecipe
-				m.isGenerating = true
-
-				// Updated loading message
-				m.generatedRecipe = "⏳ Asking local AI chef (Ollama)... This might take a few seconds."
+				m.recipeView.GotoTop()
+				m.setRecipe("⏳ Asking your AI chef (" + strings.ToLower(m.llm.Name()) + ")...")
 
 				var ingredients []string
 				for _, item := range m.foodItems {
@@ -662,18 +929,59 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if len(ingredients) == 0 {
-					m.isGenerating = false
-					m.generatedRecipe = "❌ You haven't added any items to your cart.\nGo back and press 'Right Arrow' to select ingredients."
+					m.setRecipe("❌ You haven't added any items to your cart.\nGo back and press 'Right Arrow' to select ingredients.")
 					return m, nil
 				}
 
-				return m, generateRecipeCmd(ingredients)
+				return m, m.startRecipe(recipePrompt(ingredients))
+			}
+
+		case "w":
+			if m.state == stateFood {
+				m.state = statePantryRecipe
+				m.initForm(statePantryRecipe, false)
+			}
+
+		case "b":
+			if m.state == stateBudget {
+				m.state = stateEditBudget
+				m.initForm(stateEditBudget, true)
+			} else if m.state == stateFood {
+				m.state = stateRecipeBook
+				m.cursor = 0
+			}
+
+		case "h":
+			if m.state == stateOrders {
+				m.state = stateOrderHistory
+				m.cursor = 0
+			}
+
+		case "y":
+			if m.state == stateOrders {
+				return m, m.confirmReorder()
+			}
+		case "n":
+			if m.state == stateOrders {
+				m.dismissReorder()
+			}
+
+		case "x":
+			if m.state == stateOrders && len(m.store.Orders) > 0 {
+				o := m.store.Orders[m.cursor]
+				if o.finished() {
+					m.statusMsg = "That order is already " + o.Status + "."
+					return m, nil
+				}
+				m.statusMsg = "⏳ Cancelling order..."
+				return m, cancelOrderCmd(m.ctx, m.orderProvider, o.ID)
 			}
 
 		case "c":
 			if m.state == stateFood {
 				m.state = stateFoodBuy
 				m.cursor = 0
+				m.resetSlot()
 			}
 		case "enter":
 			if m.state == stateMenu {
@@ -684,17 +992,148 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.state = stateSubs
 				case 2:
 					m.state = stateStudy
+				case 3:
+					m.state = stateOrders
+					m.cursor = 0
+					return m, m.refreshOrders()
+				case 4:
+					m.state = stateBudget
 				}
 				m.cursor = 0
 			} else if m.state == stateFoodBuy {
-				m.state = stateProcessingBuy
-				return m, processBuyCmd()
+				opts := m.checkoutOptions()
+				if m.cursor >= len(opts) {
+					return m, nil
+				}
+				if p := opts[m.cursor].problem(); p != "" {
+					m.statusMsg = "⚠️ Can't order there: " + p
+					return m, nil
+				}
+				if m.slotStarted(time.Now()) {
+					m.statusMsg = "⚠️ The " + m.slot.String() + " slot has started; pick another."
+					m.resetSlot()
+					return m, nil
+				}
+				return m, m.startCheckout(opts[m.cursor])
+			} else if m.state == stateRecipeBook && len(m.recipes) > 0 {
+				m.openRecipe(m.cursor)
+			} else if m.state == stateFood {
+				m.toggleFoodGroup()
 			}
 		}
 	}
 	return m, nil
 }
 
+// applyCategories loads fetched (or cached) categories into the model. A category
+// with a write still waiting in the outbox keeps its local content instead.
+func (m *model) applyCategories(cats []client.Category) {
+	seen := make(map[string]bool)
+	for _, cat := range cats {
+		seen[cat.Name] = true
+		if cat.Id != "" {
+			m.catIDs[cat.Name] = cat.Id
+		}
+		m.versions[cat.Name] = cat.Version
+		content := cat.Content
+		if w, ok := m.store.pendingFor(cat.Name); ok {
+			content = w.Content
+		}
+		m.applyContent(cat.Name, content)
+	}
+	// Categories created while offline only exist in the outbox so far
+	for _, w := range m.store.Outbox {
+		if !seen[w.Name] {
+			m.applyContent(w.Name, w.Content)
+		}
+	}
+}
+
+func (m *model) applyContent(name string, content json.RawMessage) {
+	var wrapper map[string]json.RawMessage
+	json.Unmarshal(content, &wrapper)
+
+	switch name {
+	case "Food":
+		// The cart is local-only state, kept in the store by item id
+		var items []FoodItem
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			for i := range items {
+				items[i].CartQty = m.store.Cart[items[i].ID]
+				items[i].trimBatches()
+			}
+			m.foodItems = items
+		}
+	case "Subscriptions":
+		var items []SubItem
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			m.subItems = items
+		}
+	case "Academics":
+		var items []StudyItem
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			m.studyItems = items
+		}
+	case "Budget":
+		var items []BudgetItem
+		if json.Unmarshal(wrapper["items"], &items) == nil && len(items) > 0 {
+			m.budget = items[0]
+		}
+	case "Orders":
+		var items []OrderRecord
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			m.orderHistory = items
+		}
+	case "Stores":
+		var items []Store
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			m.stores = items
+		}
+	case "Delivery":
+		var items []DeliveryOption
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			if err := validateDeliveryOptions(items); err != nil {
+				m.statusMsg = "⚠️ Ignoring delivery options: " + err.Error()
+				break
+			}
+			ensureIDs(items)
+			m.deliveryOpts = items
+		}
+	case "Recipes":
+		var items []Recipe
+		if json.Unmarshal(wrapper["items"], &items) == nil {
+			ensureIDs(items)
+			m.recipes = items
+		}
+	}
+}
+
+// setFocus focuses input i (an index past the inputs, like the cycle row, blurs them all).
+func (m *model) setFocus(i int) tea.Cmd {
+	m.focusIndex = i
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := 0; i < len(m.inputs); i++ {
+		if i == m.focusIndex {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+			m.inputs[i].TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = lipgloss.NewStyle()
+			m.inputs[i].TextStyle = lipgloss.NewStyle()
+		}
+		if _, bad := m.fieldErrs[i]; bad {
+			m.inputs[i].PromptStyle = errStyle
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m *model) updateInputs(msg tea.Msg) tea.Cmd {
 	cmds := make([]tea.Cmd, len(m.inputs))
 	for i := range m.inputs {
@@ -703,66 +1142,105 @@ func (m *model) updateInputs(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-func (m *model) saveForm() tea.Cmd {
-	name := m.inputs[0].Value()
-	if name == "" {
-		return nil
+// putFoodItem stores a validated item as new or over the one being edited,
+// then runs the reorder rules and returns their status line.
+func (m *model) putFoodItem(item FoodItem) string {
+	item.ID = newItemID()
+	if m.editIndex >= 0 {
+		item.ID = m.foodItems[m.editIndex].ID
+		item.CartQty = m.foodItems[m.editIndex].CartQty
+		m.foodItems[m.editIndex] = item
+	} else {
+		m.foodItems = append(m.foodItems, item)
 	}
-	m.statusMsg = "Syncing..."
+	return m.applyReorderRules()
+}
 
-	if m.state == stateAddFood {
-		price, _ := strconv.ParseFloat(m.inputs[1].Value(), 64)
-		amount, _ := strconv.Atoi(m.inputs[2].Value())
-		if amount < 0 {
-			amount = 0
+// saveForm applies the open form. An error means the input was rejected and the form stays open.
+func (m *model) saveForm() (tea.Cmd, error) {
+	if m.state == statePantryRecipe {
+		return m.startPantryRecipe()
+	}
+	if m.state == stateEditBudget {
+		errs := make(fieldErrors)
+		limit := numberField(errs, 0, m.inputs[0].Value())
+		if len(errs) > 0 {
+			return nil, m.rejectForm(errs)
 		}
-		thresh, _ := strconv.Atoi(m.inputs[3].Value())
+		m.budget.Limit = limit
+		m.statusMsg = "Syncing..."
+		return m.queueSync("Budget", []BudgetItem{m.budget}), nil
+	}
 
-		// --- NEW: AUTO-RENEW LOGIC ---
-		// If threshold is enabled (> 0) and the stock drops to or below the threshold
-		if thresh > 0 && amount <= thresh {
-			amount += 3 // Automatically buy 3 more
-			// Set a custom status message to inform the user!
-			m.statusMsg = fmt.Sprintf("Auto-renew triggered! +3 %s bought 🚚", name)
+	if m.state == stateAddFood {
+		newItem, errs := m.validateFoodForm()
+		if len(errs) > 0 {
+			return nil, m.rejectForm(errs)
 		}
-
-		newItem := FoodItem{Name: name, Price: price, Amount: amount, RenewThreshold: thresh, CartQty: 0}
-
-		if m.editIndex >= 0 {
-			newItem.CartQty = m.foodItems[m.editIndex].CartQty
-			m.foodItems[m.editIndex] = newItem
-		} else {
-			m.foodItems = append(m.foodItems, newItem)
+		m.statusMsg = "Syncing..."
+		if status := m.putFoodItem(newItem); status != "" {
+			m.statusMsg = status
 		}
-
-		return syncCategoryCmd(m.token, "Food", m.catIDs["Food"], m.foodItems)
+		return m.queueSync("Food", m.foodItems), nil
 
 	} else if m.state == stateAddSub {
-		price, _ := strconv.ParseFloat(m.inputs[1].Value(), 64)
-		date := m.inputs[2].Value()
-		if date == "" {
-			date = "TBD"
+		newItem, errs := m.validateSubForm()
+		if len(errs) > 0 {
+			return nil, m.rejectForm(errs)
 		}
-		cycle := m.subCycleChoices[m.subCycleChoice]
-
-		newItem := SubItem{Name: name, Price: price, DueDate: date, Cycle: cycle}
+		m.statusMsg = "Syncing..."
+		newItem.ID = newItemID()
+		if !newItem.DueDate.IsZero() {
+			newItem.BillingDay = newItem.DueDate.Day()
+		}
+		if m.editIndex >= 0 {
+			old := m.subItems[m.editIndex]
+			newItem.ID = old.ID
+			if newItem.DueDate.Equal(old.DueDate.Time) {
+				// An untouched date may be clamped; keep the real billing day
+				newItem.BillingDay = old.BillingDay
+			}
+		}
+		newItem.DueDate = nextDueDate(newItem.DueDate, newItem.Cycle, newItem.BillingDay)
 		if m.editIndex >= 0 {
 			m.subItems[m.editIndex] = newItem
 		} else {
 			m.subItems = append(m.subItems, newItem)
 		}
-		return syncCategoryCmd(m.token, "Subscriptions", m.catIDs["Subscriptions"], m.subItems)
+		return m.queueSync("Subscriptions", m.subItems), nil
+	}
+	return nil, nil
+}
+
+func (m model) isForm() bool {
+	return m.state == stateAddFood || m.state == stateAddSub || m.state == stateEditBudget || m.state == statePantryRecipe
+}
+
+func (m *model) stopScrape() {
+	if m.cancelScrape != nil {
+		m.cancelScrape()
+		m.cancelScrape = nil
 	}
-	return nil
 }
 
 func (m *model) goBack() {
-	if m.state == stateFoodRecipe || m.state == stateFoodBuy || m.state == stateAddFood || m.state == stateProcessingBuy {
+	if m.state == stateFoodRecipe {
+		m.stopRecipe() // nobody is left to read the rest of the stream
+	}
+	if m.state == stateFoodRecipe || m.state == stateFoodBuy || m.state == stateAddFood || m.state == stateProcessingBuy || m.state == statePantryRecipe {
 		m.state = stateFood
 	} else if m.state == stateAddSub {
 		m.state = stateSubs
 	} else if m.state == stateScrapingCanvas {
 		m.state = stateStudy
+	} else if m.state == stateOrderHistory {
+		m.state = stateOrders
+	} else if m.state == stateEditBudget {
+		m.state = stateBudget
+	} else if m.state == stateRecipeBook {
+		m.state = stateFood
+	} else if m.state == stateViewRecipe {
+		m.state = stateRecipeBook
 	} else if m.state != stateMenu {
 		m.state = stateMenu
 	}
@@ -776,6 +1254,7 @@ var (
 	selStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Bold(true)
 	checkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#EE6FF8")).Bold(true)
 	hintStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676"))
+	errStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C"))
 	boxStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2).BorderForeground(lipgloss.Color("#7D56F4"))
 )
 
@@ -783,6 +1262,20 @@ var (
 func (m model) View() string {
 	var s string
 
+	if m.state == stateEditBudget {
+		s += titleStyle.Render("💰 MONTHLY BUDGET") + "\n\n"
+		s += m.inputs[0].View() + "\n"
+		if msg, bad := m.fieldErrs[0]; bad {
+			s += errStyle.Render("    ↳ "+msg) + "\n"
+		}
+		s += "\n\n" + hintStyle.Render("[Enter: Save • Esc: Cancel]")
+		return lipgloss.NewStyle().Margin(1, 2).Render(s)
+	}
+
+	if m.state == statePantryRecipe {
+		return lipgloss.NewStyle().Margin(1, 2).Render(m.viewPantryRecipeForm())
+	}
+
 	if m.state == stateAddFood || m.state == stateAddSub {
 		if m.editIndex >= 0 {
 			s += titleStyle.Render("✏️ EDIT ITEM") + "\n\n"
@@ -791,6 +1284,13 @@ func (m model) View() string {
 		}
 		for i := range m.inputs {
 			s += m.inputs[i].View() + "\n"
+			if msg, bad := m.fieldErrs[i]; bad {
+				s += errStyle.Render("    ↳ "+msg) + "\n"
+			}
+		}
+		if m.state == stateAddSub && m.focusIndex == 2 {
+			s += lipgloss.NewStyle().MarginLeft(2).Render(renderCalendar(m.inputs[2].Value())) + "\n"
+			s += hintStyle.Render("  [ / ]: Day -/+ • { / }: Month -/+") + "\n"
 		}
 
 		if m.state == stateAddSub {
@@ -808,17 +1308,64 @@ func (m model) View() string {
 			}
 			s += "\n"
 		}
-		s += "\n\n" + hintStyle.Render("[Tab/Up/Down: Next • Left/Right: Select Cycle • Enter: Save]")
+		if m.state == stateAddFood {
+			radioPrompt := "  When low:"
+			if m.focusIndex == len(m.inputs) {
+				radioPrompt = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render("> When low:")
+			}
+			s += radioPrompt + "\n  "
+			for i, choice := range reorderChoices {
+				marker := "( )"
+				if m.reorderChoice == i {
+					marker = checkStyle.Render("(x)")
+				}
+				s += fmt.Sprintf("%s %s   ", marker, choice.label)
+			}
+			s += "\n"
+		}
+		if m.formNote != "" {
+			s += "\n" + hintStyle.Render(m.formNote)
+		}
+		if m.formErr != "" {
+			s += "\n" + errStyle.Render("❌ "+m.formErr)
+		}
+		if m.state == stateAddFood {
+			s += "\n\n" + hintStyle.Render("[Tab/Up/Down: Next • Left/Right: Select Rule • Enter: Save]")
+		} else {
+			s += "\n\n" + hintStyle.Render("[Tab/Up/Down: Next • Left/Right: Select Cycle • Enter: Save]")
+		}
 		return lipgloss.NewStyle().Margin(1, 2).Render(s)
 	}
 
 	switch m.state {
 
+	case stateConflict:
+		s += m.viewConflict()
+
+	case stateOrders:
+		s += m.viewOrders()
+
+	case stateOrderHistory:
+		s += m.viewOrderHistory()
+
+	case stateBudget:
+		s += m.viewBudget()
+
+	case stateRecipeBook:
+		s += m.viewRecipeBook()
+
+	case stateViewRecipe:
+		s += m.viewSavedRecipe()
+
 	case stateMenu:
 		// --- LEFT COLUMN: The Menu ---
 		menuStr := titleStyle.Render("⚡ PERSONAL DASHBOARD") + "\n"
 		menuStr += lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render(fmt.Sprintf("🔑 Auth: %s", m.token)) + "\n"
-		menuStr += lipgloss.NewStyle().Foreground(lipgloss.Color("#767676")).Render(m.statusMsg) + "\n\n"
+		menuStr += lipgloss.NewStyle().Foreground(lipgloss.Color("#767676")).Render(m.statusMsg) + "\n"
+		if badge := m.connectionBadge(); badge != "" {
+			menuStr += badge + "\n"
+		}
+		menuStr += "\n"
 		menuStr += renderList(m.menuChoices, m.cursor)
 		menuStr += "\n" + hintStyle.Render("[up/down: Navigate • Enter: Select • q: Quit]")
 
@@ -836,17 +1383,28 @@ func (m model) View() string {
 		alertsCount := 0
 
 		// Check Low Stock Food
+		if n := len(m.store.Reorders); n > 0 {
+			line := fmt.Sprintf("🔁 REORDER: %d order(s) to confirm in Orders", n)
+			alertLines = append(alertLines, lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(line))
+			alertsCount++
+		}
 		for _, f := range m.foodItems {
-			if f.RenewThreshold > 0 && f.Amount <= f.RenewThreshold {
-				line := fmt.Sprintf("🛒 LOW STOCK: %s (Only %d left)", f.Name, f.Amount)
+			if f.needsReorder() {
+				line := fmt.Sprintf("🛒 LOW STOCK: %s (Only %s left)", f.Name, amountText(f.Amount, f.Unit))
 				alertLines = append(alertLines, lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(line))
 				alertsCount++
 			}
 		}
 
+		// Check Expiring Food
+		for _, line := range m.expiryAlerts() {
+			alertLines = append(alertLines, line)
+			alertsCount++
+		}
+
 		// Check Upcoming Subscriptions
 		for _, s := range m.subItems {
-			d := daysUntil(s.DueDate)
+			d := s.DueDate.daysUntil()
 			if d >= 0 && d <= 3 {
 				dueText := fmt.Sprintf("in %d days", d)
 				if d == 0 {
@@ -876,6 +1434,12 @@ func (m model) View() string {
 			}
 		}
 
+		// Check Budget
+		for _, line := range m.budgetAlerts() {
+			alertLines = append(alertLines, line)
+			alertsCount++
+		}
+
 		if alertsCount == 0 {
 			alertLines = append(alertLines, lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render("✅ All caught up! No urgent tasks."))
 		}
@@ -894,89 +1458,64 @@ func (m model) View() string {
 		if len(m.foodItems) == 0 {
 			s += "    No items. Press 'a' to add one.\n"
 		} else {
-			for i, item := range m.foodItems {
+			rows := m.foodRows()
+			start, end := listWindow(m.cursor, len(rows), m.listRows(8))
+			for r := start; r < end; r++ {
+				if rows[r].index < 0 {
+					header := m.viewFoodHeader(rows[r], m.cursor == r)
+					if m.cursor == r {
+						s += selStyle.Render(header) + "\n"
+					} else {
+						s += lipgloss.NewStyle().Bold(true).Render(header) + "\n"
+					}
+					continue
+				}
+				item := m.foodItems[rows[r].index]
 				cursor := "  "
-				if m.cursor == i {
+				if m.cursor == r {
 					cursor = "▶ "
 				}
 
-				cartIndicator := "[ ]"
+				cartIndicator := "[  ]"
 				if item.CartQty > 0 {
-					cartIndicator = checkStyle.Render(fmt.Sprintf("[%2d]", item.CartQty))
-				} else {
-					cartIndicator = "[  ]"
+					cartIndicator = checkStyle.Render(fmt.Sprintf("[%2s]", amountText(item.CartQty, item.Unit)))
 				}
+				cartIndicator = lipgloss.NewStyle().Width(9).Render(cartIndicator) // "[1.5 kg]" is wider than "[ 2]"
 
 				nameCol := lipgloss.NewStyle().Width(18).Render(item.Name)
 				renewTag := "       "
 				if item.RenewThreshold > 0 {
-					renewTag = lipgloss.NewStyle().Width(7).Render(lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(fmt.Sprintf("[R≤%d]", item.RenewThreshold)))
+					renewTag = lipgloss.NewStyle().Width(7).Render(lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render("[R≤" + fmtQty(item.RenewThreshold) + "]"))
 				}
 
-				line := fmt.Sprintf("  %s %s %s (Stock: %2d) %s -  $%.2f", cursor, cartIndicator, nameCol, item.Amount, renewTag, item.Price)
-				if m.cursor == i {
+				line := fmt.Sprintf("  %s %s %s (Stock: %6s) %s %s -  %s", cursor, cartIndicator, nameCol, amountText(item.Amount, item.Unit), renewTag, expiryTag(item), item.priceText())
+				if m.cursor == r {
 					s += selStyle.Render(line) + "\n"
 				} else {
 					s += itemStyle.Render(line) + "\n"
 				}
 			}
+			s += pageIndicator(start, end, len(rows))
 		}
-		s += "\n" + hintStyle.Render("[Left/Right: Add Qty • a: Add • e: Edit • d: Del • r: Recipe • c: Checkout • p: Push to Phone]")
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render(m.statusMsg)
+		s += "\n" + hintStyle.Render("[Left/Right: Add Qty • a: Add • e: Edit • d: Del • g: Group by aisle/storage • Enter: Fold group • r: Recipe • w: Cook from stock • b: Recipe Book • c: Checkout • p: Push to Phone]")
+		s += "\n" + m.renderStatus()
 
 	case stateFoodRecipe:
-		s += titleStyle.Render("🍳 AI GENERATED RECIPE (OLLAMA)") + "\n\n"
-
-		// Set a max width so the AI's text wraps cleanly on screen
-		recipeBox := boxStyle.Copy().Width(60).Render(m.generatedRecipe)
+		s += titleStyle.Render("🍳 AI GENERATED RECIPE ("+m.llm.Name()+")") + "\n\n"
 
+		s += m.viewRecipeBox()
 		if m.isGenerating {
-			s += lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(m.generatedRecipe)
+			s += "\n\n" + hintStyle.Render("[up/down/pgup/pgdn: Scroll • Esc: Stop generating]")
+		} else if !m.recipeFinished() {
+			s += "\n\n" + hintStyle.Render("[up/down/pgup/pgdn: Scroll • Esc: Back]")
 		} else {
-			s += recipeBox
-		}
-		if !m.isGenerating {
-			s += "\n\n" + hintStyle.Render("[Esc: Back]")
+			s += "\n\n" + hintStyle.Render("[up/down/pgup/pgdn: Scroll • s: Save to Recipe Book • m: Add missing to cart • c: Cooked it • Esc: Back]")
 		}
+		s += "\n" + m.renderStatus()
 
 	case stateFoodBuy:
-		s += titleStyle.Render("🚚 CHECKOUT") + "\n"
-		var total float64
-		var count int
-		var cartSummary string
-
-		for _, item := range m.foodItems {
-			if item.CartQty > 0 {
-				cost := item.Price * float64(item.CartQty)
-				total += cost
-				count++
-				cartSummary += fmt.Sprintf("  %dx %-15s - $%.2f\n", item.CartQty, item.Name, cost)
-			}
-		}
-
-		if count == 0 {
-			s += boxStyle.Render("🛒 Cart empty.\nGo back and press Right Arrow to add items to cart.")
-		} else {
-			s += fmt.Sprintf("Items in Cart:\n%s\nSubtotal: $%.2f\n\nChoose delivery:\n\n", cartSummary, total)
-			for i, choice := range m.buyChoices {
-				cursor := "  "
-				if m.cursor == i {
-					cursor = "▶ "
-				}
-				line := fmt.Sprintf("  %s %s", cursor, choice)
-				if m.cursor == i {
-					s += selStyle.Render(line) + "\n"
-				} else {
-					s += itemStyle.Render(line) + "\n"
-				}
-			}
-			ship := 0.0
-			if m.cursor == 0 {
-				ship = 3.00
-			}
-			s += fmt.Sprintf("\n💰 TOTAL TO PAY: $%.2f\n", total+ship)
-		}
-		s += "\n" + hintStyle.Render("[Enter: Buy • Esc: Cancel]")
+		s += m.viewCheckout()
+		s += "\n" + m.renderStatus()
 
 	case stateProcessingBuy:
 		s += titleStyle.Render("🚚 PROCESSING ORDER") + "\n\n"
@@ -988,7 +1527,9 @@ func (m model) View() string {
 		if len(m.subItems) == 0 {
 			s += "    No items.\n"
 		} else {
-			for i, item := range m.subItems {
+			start, end := listWindow(m.cursor, len(m.subItems), m.listRows(8))
+			for i := start; i < end; i++ {
+				item := m.subItems[i]
 				cursor := "  "
 				if m.cursor == i {
 					cursor = "▶ "
@@ -1002,22 +1543,25 @@ func (m model) View() string {
 					s += itemStyle.Render(line) + "\n"
 				}
 			}
+			s += pageIndicator(start, end, len(m.subItems))
 		}
 		s += "\n" + hintStyle.Render("[a: Add • e: Edit • d: Delete • up/down: Navigate • Esc: Back]")
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render(m.statusMsg)
+		s += "\n" + m.renderStatus()
 
 	// NEW: The loading screen that shows while fetching Canvas assignments
 	case stateScrapingCanvas:
 		s += titleStyle.Render("📚 ACADEMICS (Automated Scraper)") + "\n\n"
 		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render("⏳ Connecting to Canvas LMS... bypassing CAPTCHA... extracting assignments...")
-		s += "\n\n" + hintStyle.Render("[Scraping... please wait]")
+		s += "\n\n" + hintStyle.Render("[Scraping... please wait • Esc: Cancel]")
 
 	case stateStudy:
 		s += titleStyle.Render("📚 ACADEMICS") + "\n\n"
 		if len(m.studyItems) == 0 {
 			s += "    No pending assignments.\n"
 		} else {
-			for i, item := range m.studyItems {
+			start, end := listWindow(m.cursor, len(m.studyItems), m.listRows(9))
+			for i := start; i < end; i++ {
+				item := m.studyItems[i]
 				cursor := "  "
 				if m.cursor == i {
 					cursor = "▶ "
@@ -1030,9 +1574,10 @@ func (m model) View() string {
 					s += itemStyle.Render(line) + "\n"
 				}
 			}
+			s += pageIndicator(start, end, len(m.studyItems))
 		}
 		s += "\n" + hintStyle.Render("[s: Sync Canvas • up/down: Navigate • Esc: Back]")
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render(m.statusMsg)
+		s += "\n" + m.renderStatus()
 	}
 	return lipgloss.NewStyle().Margin(1, 2).Render(s)
 }
@@ -1051,8 +1596,32 @@ func renderList(items []string, cursor int) string {
 
 func main() {
 	tokenPtr := flag.String("token", "", "User authentication token (Mandatory for first run)")
+	configPtr := flag.String("config", "", "Path to config file (default $XDG_CONFIG_HOME/dashboard/config.toml)")
+	backendPtr := flag.String("backend", "", "Backend base URL (overrides config)")
+	ntfyPtr := flag.String("ntfy", "", "ntfy topic URL for phone notifications (overrides config)")
+	ollamaPtr := flag.String("ollama", "", "Ollama base URL (overrides config)")
+	modelPtr := flag.String("model", "", "Ollama model used for recipes (overrides config)")
+	llmPtr := flag.String("llm", "", "Recipe LLM provider: ollama, openai or fake (overrides config)")
 	flag.Parse()
 
+	configPath := *configPtr
+	if configPath == "" {
+		configPath = os.Getenv("DASHBOARD_CONFIG")
+	}
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Println("❌ Error loading config:", err)
+		os.Exit(1)
+	}
+	flagOverride(&cfg.Backend.URL, *backendPtr)
+	flagOverride(&cfg.Ntfy.URL, *ntfyPtr)
+	flagOverride(&cfg.Ollama.URL, *ollamaPtr)
+	flagOverride(&cfg.Ollama.Model, *modelPtr)
+	flagOverride(&cfg.LLM.Provider, *llmPtr)
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Println("❌ Error finding home directory:", err)
@@ -1060,6 +1629,7 @@ func main() {
 	}
 
 	tokenFile := filepath.Join(homeDir, ".dashboard_token")
+	cacheFile := filepath.Join(homeDir, ".dashboard_cache.json")
 	var finalToken string
 
 	if *tokenPtr != "" {
@@ -1084,7 +1654,29 @@ func main() {
 		}
 	}
 
-	p := tea.NewProgram(initialModel(finalToken), tea.WithAltScreen())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	orders, err := newOrderProvider(cfg)
+	if err != nil {
+		fmt.Println("❌ Error:", err)
+		os.Exit(1)
+	}
+
+	llm, err := newLLMProvider(cfg)
+	if err != nil {
+		fmt.Println("❌ Error:", err)
+		os.Exit(1)
+	}
+
+	m := initialModel(ctx, cfg, newAPIClient(cfg), orders, llm, openLocalStore(cacheFile, finalToken), finalToken)
+	if args := flag.Args(); len(args) > 0 {
+		code := runCLI(m, args)
+		cancel()
+		os.Exit(code)
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error starting TUI: %v\n", err)
 		os.Exit(1)
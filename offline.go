@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tui/client"
+)
+
+// How long to wait before probing the backend again while offline.
+const offlineRetryInterval = 15 * time.Second
+
+// --- LOCAL STORE ---
+// The last categories fetched from the backend plus an outbox of writes that
+// have not been acknowledged yet. Every write goes through the outbox first, so
+// an edit made while the backend is down survives a restart and is replayed later.
+type pendingWrite struct {
+	Seq         int             `json:"seq"`
+	Name        string          `json:"name"`
+	CatID       string          `json:"catId"`
+	BaseVersion int             `json:"baseVersion"` // remote version the edit was made on top of
+	Base        json.RawMessage `json:"base"`        // remote content at BaseVersion, used to diff
+	Content     json.RawMessage `json:"content"`
+	QueuedAt    time.Time       `json:"queuedAt"`
+}
+
+type localStore struct {
+	path string
+
+	Token      string            `json:"token"`
+	Categories []client.Category `json:"categories"`
+	Outbox     []pendingWrite    `json:"outbox"`
+	NextSeq    int               `json:"nextSeq"`
+	Orders     []Order           `json:"orders"`   // placed orders, newest first
+	Reorders   []OrderRequest    `json:"reorders"` // orders proposed by reorder rules, awaiting confirmation
+
+	// The cart is not synced, so it is kept here to survive a restart
+	Cart map[string]float64 `json:"cart,omitempty"` // item id -> cart quantity
+	// Items whose current shortage a reorder rule has already dealt with
+	// (carted, proposed, or found on its way), so taking it out of the cart
+	// or dismissing the reorder sticks until stock recovers
+	Reordered map[string]bool `json:"reordered,omitempty"`
+}
+
+// openLocalStore loads the store at path. A missing or unreadable file, or one
+// written for another token, yields an empty store.
+func openLocalStore(path, token string) *localStore {
+	s := &localStore{path: path, Token: token}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	var saved localStore
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Token != token {
+		return s
+	}
+	saved.path = path
+	return &saved
+}
+
+func (s *localStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	// Write-then-rename so a crash never leaves a half-written cache behind
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// enqueue records a full-category write. A newer write for the same category
+// replaces any older one still waiting, since each carries the whole item list.
+// The replacement keeps the older write's base: the local content still
+// descends from it, whatever has been fetched since.
+func (s *localStore) enqueue(name, catID string, baseVersion int, base, content json.RawMessage) pendingWrite {
+	s.NextSeq++
+	w := pendingWrite{Seq: s.NextSeq, Name: name, CatID: catID, BaseVersion: baseVersion, Base: base, Content: content, QueuedAt: time.Now()}
+	for i := range s.Outbox {
+		if s.Outbox[i].Name == name {
+			w.BaseVersion = s.Outbox[i].BaseVersion
+			w.Base = s.Outbox[i].Base
+			s.Outbox[i] = w
+			return w
+		}
+	}
+	s.Outbox = append(s.Outbox, w)
+	return w
+}
+
+// ack drops the write with the given sequence number, if it is still queued.
+func (s *localStore) ack(seq int) {
+	for i := range s.Outbox {
+		if s.Outbox[i].Seq == seq {
+			s.Outbox = append(s.Outbox[:i], s.Outbox[i+1:]...)
+			return
+		}
+	}
+}
+
+// rebase moves a queued write for name onto a newer remote version and content,
+// used once a write it was stacked on top of has been accepted.
+func (s *localStore) rebase(name string, version int, base json.RawMessage) {
+	for i := range s.Outbox {
+		if s.Outbox[i].Name == name {
+			s.Outbox[i].BaseVersion = version
+			s.Outbox[i].Base = base
+		}
+	}
+}
+
+// recordRemote updates the cached remote copy of a category after a write was accepted.
+func (s *localStore) recordRemote(name, catID string, version int, content json.RawMessage) {
+	for i := range s.Categories {
+		if s.Categories[i].Name == name {
+			s.Categories[i].Content = content
+			s.Categories[i].Version = version
+			if catID != "" {
+				s.Categories[i].Id = catID
+			}
+			return
+		}
+	}
+	s.Categories = append(s.Categories, client.Category{Id: catID, UserId: s.Token, Name: name, Content: content, Version: version})
+}
+
+// remoteContent returns the last fetched content of the named category.
+func (s *localStore) remoteContent(name string) json.RawMessage {
+	for _, c := range s.Categories {
+		if c.Name == name {
+			return c.Content
+		}
+	}
+	return nil
+}
+
+func (s *localStore) pendingFor(name string) (pendingWrite, bool) {
+	for _, w := range s.Outbox {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return pendingWrite{}, false
+}
+
+// --- MESSAGES ---
+type syncSuccessMsg struct {
+	seq     int
+	name    string
+	catID   string
+	version int
+	content json.RawMessage // what the backend now holds
+}
+
+type syncFailedMsg struct {
+	seq  int
+	name string
+	err  error
+}
+
+type fetchFailedMsg struct{ err error }
+type retryOnlineMsg struct{}
+
+// isOfflineErr reports whether err means "backend unreachable" rather than
+// "backend rejected the request". Only transport failures, timeouts and 5xx
+// count; anything else (say, a 200 whose body does not decode) would fail
+// the same way on every replay.
+func isOfflineErr(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if code := client.StatusCode(err); code != 0 {
+		return code >= 500
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// --- COMMANDS ---
+
+// syncCategoryCmd sends a queued write. Existing categories get only the items
+// that changed since the base; the whole category is written when the backend
+// has no item endpoint or the base predates item ids.
+func syncCategoryCmd(ctx context.Context, api client.API, token string, w pendingWrite) tea.Cmd {
+	return func() tea.Msg {
+		cat := client.Category{Id: w.CatID, UserId: token, Name: w.Name, Content: w.Content, Version: w.BaseVersion}
+
+		done := func(cat client.Category, err error) tea.Msg {
+			if err != nil {
+				return syncFailedMsg{seq: w.Seq, name: w.Name, err: fmt.Errorf("sync %s: %w", w.Name, err)}
+			}
+			return syncSuccessMsg{seq: w.Seq, name: w.Name, catID: cat.Id, version: cat.Version, content: w.Content}
+		}
+
+		if w.CatID == "" {
+			return done(api.CreateCategory(ctx, cat))
+		}
+		if ops, ok := diffItems(w.Base, w.Content); ok && w.Base != nil {
+			if len(ops) == 0 {
+				return done(cat, nil)
+			}
+			patched, err := api.PatchCategoryItems(ctx, w.CatID, w.BaseVersion, ops)
+			if !errors.Is(err, client.ErrPatchUnsupported) {
+				if patched.Id == "" {
+					patched.Id = w.CatID
+				}
+				return done(patched, err)
+			}
+		}
+		return done(api.UpdateCategory(ctx, cat))
+	}
+}
+
+func retryOnlineCmd() tea.Cmd {
+	return tea.Tick(offlineRetryInterval, func(time.Time) tea.Msg { return retryOnlineMsg{} })
+}
+
+// --- MODEL HELPERS ---
+
+// queueSync persists items for the named category to the outbox and, when the
+// backend is reachable and no write for that category is in flight, sends it.
+func (m *model) queueSync(name string, items interface{}) tea.Cmd {
+	content, err := json.Marshal(map[string]interface{}{"items": items})
+	if err != nil {
+		m.statusMsg = "Error: " + err.Error()
+		return nil
+	}
+	m.store.enqueue(name, m.catIDs[name], m.versions[name], m.store.remoteContent(name), content)
+	m.saveStore()
+
+	if m.offline {
+		m.statusMsg = "Offline — change saved locally, will sync later"
+		return nil
+	}
+	return m.sendPending(name)
+}
+
+// sendPending sends the queued write for name unless one is already in flight.
+func (m *model) sendPending(name string) tea.Cmd {
+	if m.inFlight[name] {
+		return nil
+	}
+	w, ok := m.store.pendingFor(name)
+	if !ok {
+		return nil
+	}
+	if w.CatID == "" {
+		w.CatID = m.catIDs[name]
+	}
+	m.inFlight[name] = true
+	return syncCategoryCmd(m.ctx, m.api, m.token, w)
+}
+
+// replayOutbox sends every queued write, oldest first.
+func (m *model) replayOutbox() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, w := range m.store.Outbox {
+		cmds = append(cmds, m.sendPending(w.Name))
+	}
+	return tea.Batch(cmds...)
+}
+
+// goOffline flips the model into offline mode and schedules a reconnect probe.
+func (m *model) goOffline() tea.Cmd {
+	m.offline = true
+	if m.retryScheduled {
+		return nil
+	}
+	m.retryScheduled = true
+	return retryOnlineCmd()
+}
+
+func (m *model) saveStore() {
+	m.store.Cart = make(map[string]float64)
+	for _, f := range m.foodItems {
+		if f.CartQty > 0 {
+			m.store.Cart[f.ID] = f.CartQty
+		}
+	}
+	if err := m.store.save(); err != nil {
+		m.statusMsg = "⚠️ Could not write local cache: " + err.Error()
+	}
+}
+
+// connectionBadge renders the "offline / N pending changes" indicator, or "" when in sync.
+func (m model) connectionBadge() string {
+	n := len(m.store.Outbox)
+	switch {
+	case m.offline && n > 0:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C")).Render(fmt.Sprintf("⚠️ Offline • %d pending change(s)", n))
+	case m.offline:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C")).Render("⚠️ Offline • showing cached data")
+	case n > 0:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(fmt.Sprintf("⏳ %d pending change(s)", n))
+	}
+	return ""
+}
+
+// renderStatus renders the status line shown under each list, followed by the connection badge.
+func (m model) renderStatus() string {
+	s := lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Render(m.statusMsg)
+	if badge := m.connectionBadge(); badge != "" {
+		s += "\n" + badge
+	}
+	return s
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"tui/client"
+)
+
+func TestIsOfflineErr(t *testing.T) {
+	ctx := context.Background()
+	reply := func(status int, body string) error {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+		defer srv.Close()
+		_, err := client.New(srv.URL).ListCategories(ctx, "u")
+		return err
+	}
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	_, refused := client.New(down.URL).ListCategories(ctx, "u")
+
+	tests := []struct {
+		name    string
+		err     error
+		offline bool
+	}{
+		{"connection refused", refused, true},
+		{"server error", reply(http.StatusServiceUnavailable, "down"), true},
+		{"timeout", fmt.Errorf("sync: %w", context.DeadlineExceeded), true},
+		{"bad request", reply(http.StatusBadRequest, "no"), false},
+		{"undecodable 200", reply(http.StatusOK, "{not json"), false},
+		{"cancelled", context.Canceled, false},
+		{"marshal error", fmt.Errorf("sync: %w", &json.UnsupportedValueError{Str: "NaN"}), false},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Fatalf("%s: no error to classify", tt.name)
+		}
+		if got := isOfflineErr(tt.err); got != tt.offline {
+			t.Errorf("%s (%v): offline = %v, want %v", tt.name, tt.err, got, tt.offline)
+		}
+	}
+}
+
+func TestSyncCategoryCmd(t *testing.T) {
+	base := json.RawMessage(`{"items":[{"id":"a","name":"Milk"},{"id":"b","name":"Eggs"}]}`)
+	next := json.RawMessage(`{"items":[{"id":"a","name":"Milk","amount":2}]}`)
+	legacy := json.RawMessage(`{"items":[{"name":"Milk"}]}`)
+
+	tests := []struct {
+		name      string
+		w         pendingWrite
+		patchErr  error
+		wantCalls []string
+		wantSaved string // content the fake holds afterwards
+	}{
+		{
+			name:      "new category is created",
+			w:         pendingWrite{Name: "Food", Content: next},
+			wantCalls: []string{"CreateCategory"},
+			wantSaved: string(next),
+		},
+		{
+			name:      "changed items are patched",
+			w:         pendingWrite{Name: "Food", CatID: "c1", BaseVersion: 1, Base: base, Content: next},
+			wantCalls: []string{"PatchCategoryItems"},
+			wantSaved: string(next),
+		},
+		{
+			name:      "no item endpoint falls back to PUT",
+			w:         pendingWrite{Name: "Food", CatID: "c1", BaseVersion: 1, Base: base, Content: next},
+			patchErr:  client.ErrPatchUnsupported,
+			wantCalls: []string{"PatchCategoryItems", "UpdateCategory"},
+			wantSaved: string(next),
+		},
+		{
+			name:      "base without ids is rewritten whole",
+			w:         pendingWrite{Name: "Food", CatID: "c1", BaseVersion: 1, Base: legacy, Content: next},
+			wantCalls: []string{"UpdateCategory"},
+			wantSaved: string(next),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(client.Category{Id: "c1", Name: "Food", Content: base, Version: 1})
+			api.patchErr = tt.patchErr
+
+			msg := syncCategoryCmd(context.Background(), api, "u", tt.w)()
+			ok, isOK := msg.(syncSuccessMsg)
+			if !isOK {
+				t.Fatalf("got %#v, want syncSuccessMsg", msg)
+			}
+			if !reflect.DeepEqual(api.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", api.calls, tt.wantCalls)
+			}
+			saved := api.cats[ok.catID].Content
+			if !jsonEqual(saved, json.RawMessage(tt.wantSaved)) {
+				t.Errorf("backend holds %s, want %s", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+func TestSyncCategoryCmdConflict(t *testing.T) {
+	base := json.RawMessage(`{"items":[{"id":"a","name":"Milk"}]}`)
+	next := json.RawMessage(`{"items":[{"id":"a","name":"Milk","amount":2}]}`)
+
+	tests := []struct {
+		name      string
+		w         pendingWrite
+		wantCalls []string
+	}{
+		{"full write", pendingWrite{Name: "Food", CatID: "c1", BaseVersion: 4, Content: next}, []string{"UpdateCategory"}},
+		{"item patch", pendingWrite{Name: "Food", CatID: "c1", BaseVersion: 4, Base: base, Content: next}, []string{"PatchCategoryItems"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(client.Category{Id: "c1", Name: "Food", Content: base, Version: 5})
+
+			msg := syncCategoryCmd(context.Background(), api, "u", tt.w)()
+			failed, ok := msg.(syncFailedMsg)
+			if !ok || !client.IsConflict(failed.err) {
+				t.Fatalf("got %#v, want a conflict", msg)
+			}
+			if !reflect.DeepEqual(api.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", api.calls, tt.wantCalls)
+			}
+			if !jsonEqual(api.cats["c1"].Content, base) {
+				t.Errorf("stale write landed: %s", api.cats["c1"].Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tui/client"
+)
+
+// --- ORDERS ---
+const (
+	orderPending   = "pending"
+	orderConfirmed = "confirmed"
+	orderShipping  = "out_for_delivery"
+	orderDelivered = "delivered"
+	orderCancelled = "cancelled"
+	orderFailed    = "failed"
+)
+
+type OrderLine struct {
+	ItemID    string  `json:"itemId"`
+	Name      string  `json:"name"`
+	Qty       float64 `json:"qty"`
+	Unit      string  `json:"unit,omitempty"` // the item's stock unit; "" means pieces
+	UnitPrice float64 `json:"unitPrice"`      // per Unit
+}
+
+type OrderRequest struct {
+	UserID      string        `json:"userId"`
+	Lines       []OrderLine   `json:"lines"`
+	Store       string        `json:"store,omitempty"`
+	Delivery    string        `json:"delivery"`
+	DeliveryFee float64       `json:"deliveryFee"`
+	Slot        *DeliverySlot `json:"slot,omitempty"` // nil: as soon as possible
+}
+
+type Order struct {
+	ID          string        `json:"id"`
+	Status      string        `json:"status"`
+	Lines       []OrderLine   `json:"lines"`
+	Store       string        `json:"store,omitempty"`
+	Delivery    string        `json:"delivery"`
+	DeliveryFee float64       `json:"deliveryFee"`
+	Slot        *DeliverySlot `json:"slot,omitempty"`
+	Total       float64       `json:"total"`
+	PlacedAt    time.Time     `json:"placedAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+
+	// Set locally once the delivered items have been added to Food stock
+	StockApplied bool `json:"stockApplied,omitempty"`
+}
+
+func (o Order) finished() bool {
+	return o.Status == orderDelivered || o.Status == orderCancelled || o.Status == orderFailed
+}
+
+// OrderProvider places and tracks grocery orders.
+type OrderProvider interface {
+	PlaceOrder(ctx context.Context, req OrderRequest) (Order, error)
+	OrderStatus(ctx context.Context, id string) (Order, error)
+	CancelOrder(ctx context.Context, id string) (Order, error)
+}
+
+func newOrderProvider(cfg Config) (OrderProvider, error) {
+	switch cfg.Orders.Provider {
+	case "", "mock":
+		return newMockOrderProvider(), nil
+	case "http":
+		if cfg.Orders.URL == "" {
+			return nil, fmt.Errorf("orders: provider \"http\" needs orders.url")
+		}
+		return &httpOrderProvider{baseURL: strings.TrimRight(cfg.Orders.URL, "/"), http: &http.Client{Timeout: cfg.Backend.Timeout}}, nil
+	}
+	return nil, fmt.Errorf("orders: unknown provider %q", cfg.Orders.Provider)
+}
+
+// --- MOCK PROVIDER ---
+// Simulates a store: orders are confirmed, shipped and delivered on a fixed
+// schedule after placement. The placement time lives in the id, so orders
+// keep progressing across restarts.
+var mockOrderSchedule = []struct {
+	after  time.Duration
+	status string
+}{
+	{30 * time.Second, orderDelivered},
+	{10 * time.Second, orderShipping},
+	{2 * time.Second, orderConfirmed},
+}
+
+type mockOrderProvider struct {
+	mu        sync.Mutex
+	orders    map[string]Order
+	cancelled map[string]bool
+}
+
+func newMockOrderProvider() *mockOrderProvider {
+	return &mockOrderProvider{orders: make(map[string]Order), cancelled: make(map[string]bool)}
+}
+
+func (p *mockOrderProvider) PlaceOrder(ctx context.Context, req OrderRequest) (Order, error) {
+	now := time.Now()
+	o := Order{
+		ID:          "mock-" + strconv.FormatInt(now.UnixNano(), 36),
+		Status:      orderPending,
+		Lines:       req.Lines,
+		Store:       req.Store,
+		Delivery:    req.Delivery,
+		DeliveryFee: req.DeliveryFee,
+		Slot:        req.Slot,
+		Total:       linesTotal(req.Lines) + req.DeliveryFee,
+		PlacedAt:    now,
+		UpdatedAt:   now,
+	}
+	p.mu.Lock()
+	p.orders[o.ID] = o
+	p.mu.Unlock()
+	return o, nil
+}
+
+func (p *mockOrderProvider) OrderStatus(ctx context.Context, id string) (Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.orders[id]
+	if !ok {
+		nanos, err := strconv.ParseInt(strings.TrimPrefix(id, "mock-"), 36, 64)
+		if err != nil {
+			return Order{}, fmt.Errorf("unknown order %s", id)
+		}
+		o = Order{ID: id, PlacedAt: time.Unix(0, nanos)}
+	}
+	if p.cancelled[id] {
+		o.Status = orderCancelled
+		return o, nil
+	}
+	o.Status = orderPending
+	for _, step := range mockOrderSchedule {
+		if time.Since(o.PlacedAt) >= step.after {
+			o.Status = step.status
+			break
+		}
+	}
+	o.UpdatedAt = time.Now()
+	return o, nil
+}
+
+func (p *mockOrderProvider) CancelOrder(ctx context.Context, id string) (Order, error) {
+	o, err := p.OrderStatus(ctx, id)
+	if err != nil {
+		return o, err
+	}
+	if o.Status == orderShipping || o.Status == orderDelivered {
+		return o, fmt.Errorf("order is already %s", strings.ReplaceAll(o.Status, "_", " "))
+	}
+	p.mu.Lock()
+	p.cancelled[id] = true
+	p.mu.Unlock()
+	o.Status = orderCancelled
+	return o, nil
+}
+
+// --- HTTP PROVIDER ---
+// Talks to an order service at orders.url:
+//
+//	POST {url}/orders              place (body: OrderRequest)
+//	GET  {url}/orders/{id}         status
+//	POST {url}/orders/{id}/cancel  cancel
+//
+// Every endpoint answers with an Order.
+type httpOrderProvider struct {
+	baseURL string
+	http    *http.Client
+}
+
+func (p *httpOrderProvider) PlaceOrder(ctx context.Context, req OrderRequest) (Order, error) {
+	return p.do(ctx, http.MethodPost, "/orders", req)
+}
+
+func (p *httpOrderProvider) OrderStatus(ctx context.Context, id string) (Order, error) {
+	return p.do(ctx, http.MethodGet, "/orders/"+url.PathEscape(id), nil)
+}
+
+func (p *httpOrderProvider) CancelOrder(ctx context.Context, id string) (Order, error) {
+	return p.do(ctx, http.MethodPost, "/orders/"+url.PathEscape(id)+"/cancel", nil)
+}
+
+func (p *httpOrderProvider) do(ctx context.Context, method, path string, in any) (Order, error) {
+	var o Order
+	err := client.DoJSON(ctx, p.http, method, p.baseURL+path, nil, in, &o)
+	return o, err
+}
+
+func linesTotal(lines []OrderLine) float64 {
+	var total float64
+	for _, l := range lines {
+		total += l.UnitPrice * l.Qty
+	}
+	return total
+}
+
+// --- MESSAGES & COMMANDS ---
+// orderPlacedMsg carries the request too: the cart is cleared from what was
+// asked for, since a provider may answer without the lines.
+type orderPlacedMsg struct {
+	order Order
+	req   OrderRequest
+}
+type orderUpdatedMsg struct{ order Order }
+type orderPollMsg struct{}
+type orderStatusFailedMsg struct{ err error }
+
+func placeOrderCmd(ctx context.Context, p OrderProvider, req OrderRequest) tea.Cmd {
+	return func() tea.Msg {
+		o, err := p.PlaceOrder(ctx, req)
+		if err != nil {
+			return errMsg{fmt.Errorf("placing order: %w", err)}
+		}
+		return orderPlacedMsg{o, req}
+	}
+}
+
+func orderStatusCmd(ctx context.Context, p OrderProvider, id string) tea.Cmd {
+	return func() tea.Msg {
+		o, err := p.OrderStatus(ctx, id)
+		if err != nil {
+			return orderStatusFailedMsg{fmt.Errorf("order %s: %w", id, err)}
+		}
+		return orderUpdatedMsg{o}
+	}
+}
+
+func cancelOrderCmd(ctx context.Context, p OrderProvider, id string) tea.Cmd {
+	return func() tea.Msg {
+		o, err := p.CancelOrder(ctx, id)
+		if err != nil {
+			return errMsg{fmt.Errorf("cancelling order: %w", err)}
+		}
+		return orderUpdatedMsg{o}
+	}
+}
+
+// --- MODEL HELPERS ---
+
+// schedulePoll starts the status polling loop if any order is still open.
+func (m *model) schedulePoll() tea.Cmd {
+	if m.pollScheduled {
+		return nil
+	}
+	for _, o := range m.store.Orders {
+		if !o.finished() {
+			m.pollScheduled = true
+			return tea.Tick(m.cfg.Orders.PollInterval, func(time.Time) tea.Msg { return orderPollMsg{} })
+		}
+	}
+	return nil
+}
+
+func (m *model) pollOrders() tea.Cmd {
+	m.pollScheduled = false
+	return m.refreshOrders()
+}
+
+// refreshOrders asks the provider for the status of every open order.
+func (m model) refreshOrders() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, o := range m.store.Orders {
+		if !o.finished() {
+			cmds = append(cmds, orderStatusCmd(m.ctx, m.orderProvider, o.ID))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// trackOrder records an order update. Stock is only added once the order is delivered.
+func (m *model) trackOrder(o Order) tea.Cmd {
+	idx := -1
+	for i := range m.store.Orders {
+		if m.store.Orders[i].ID == o.ID {
+			idx = i
+			break
+		}
+	}
+	justFinished := o.finished()
+	if idx < 0 {
+		m.store.Orders = append([]Order{o}, m.store.Orders...)
+		idx = 0
+	} else {
+		// Providers may answer status calls with a bare order; keep what we already know
+		prev := m.store.Orders[idx]
+		justFinished = o.finished() && !prev.finished()
+		if len(o.Lines) == 0 {
+			o.Lines, o.Store, o.Delivery, o.DeliveryFee, o.Slot, o.Total = prev.Lines, prev.Store, prev.Delivery, prev.DeliveryFee, prev.Slot, prev.Total
+		}
+		if o.PlacedAt.IsZero() {
+			o.PlacedAt = prev.PlacedAt
+		}
+		o.StockApplied = prev.StockApplied
+		m.store.Orders[idx] = o
+	}
+
+	var cmds []tea.Cmd
+	if o.Status == orderDelivered && !o.StockApplied {
+		m.store.Orders[idx].StockApplied = true
+		for _, line := range o.Lines {
+			for i := range m.foodItems {
+				if m.foodItems[i].ID == line.ItemID {
+					m.foodItems[i].Amount = roundQty(m.foodItems[i].Amount + line.Qty)
+				}
+			}
+		}
+		m.statusMsg = "📦 Order delivered! Stock updated."
+		cmds = append(cmds, m.queueSync("Food", m.foodItems), m.recordOrder(m.store.Orders[idx]))
+	}
+	if justFinished {
+		// Delivered stock may still be short, and cancelled lines are no longer on their way
+		for _, l := range o.Lines {
+			delete(m.store.Reordered, l.ItemID)
+		}
+		if status := m.applyReorderRules(); status != "" {
+			m.statusMsg = status
+		}
+	}
+	m.saveStore()
+	cmds = append(cmds, m.schedulePoll())
+	return tea.Batch(cmds...)
+}
+
+// deliveryText is how an order is fulfilled: "🚚 Delivery · Aldi".
+func deliveryText(delivery, store string) string {
+	if store == "" {
+		return delivery
+	}
+	return delivery + " · " + store
+}
+
+// --- VIEW ---
+var orderStatusColors = map[string]string{
+	orderPending:   "#767676",
+	orderConfirmed: "#E1B12C",
+	orderShipping:  "#7D56F4",
+	orderDelivered: "#04B575",
+	orderCancelled: "#767676",
+	orderFailed:    "#FF4C4C",
+}
+
+func (m model) viewOrders() string {
+	s := titleStyle.Render("📦 ORDERS") + "\n"
+	if len(m.store.Orders) == 0 {
+		s += "    No orders yet. Check out from the Food screen to place one.\n"
+	}
+	start, end := listWindow(m.cursor, len(m.store.Orders), m.listRows(8))
+	for i := start; i < end; i++ {
+		o := m.store.Orders[i]
+		cursor := "  "
+		if m.cursor == i {
+			cursor = "▶ "
+		}
+		count := len(o.Lines)
+		status := lipgloss.NewStyle().Width(18).Foreground(lipgloss.Color(orderStatusColors[o.Status])).Render(strings.ReplaceAll(o.Status, "_", " "))
+		line := fmt.Sprintf("  %s %s  %s  %2d line(s)  $%7.2f  %s", cursor, o.PlacedAt.Local().Format("Jan 02 15:04"), status, count, o.Total, deliveryText(o.Delivery, o.Store))
+		if o.Slot != nil && !o.finished() {
+			line += "  ⏰ " + o.Slot.String()
+		}
+		if m.cursor == i {
+			s += selStyle.Render(line) + "\n"
+		} else {
+			s += itemStyle.Render(line) + "\n"
+		}
+	}
+	s += pageIndicator(start, end, len(m.store.Orders))
+	if pending := m.viewPendingReorder(); pending != "" {
+		s += "\n" + pending
+	}
+	s += "\n" + hintStyle.Render("[x: Cancel order • r: Refresh • h: History & Spending • up/down: Navigate • Esc: Back]")
+	s += "\n" + m.renderStatus()
+	return s
+}
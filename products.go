@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- PRODUCT DATABASE ---
+// Barcodes are looked up in a local Open Food Facts dump, either the JSONL
+// export (one product per line) or the tab-separated CSV export, optionally
+// gzipped. OFF itself has no prices; a dump trimmed down for this app may add
+// a "price" field or column, which then prefills the form too.
+
+var errProductNotFound = errors.New("product not found")
+
+type Product struct {
+	Code       string
+	Name       string
+	Brand      string
+	Quantity   string // as printed on the package, e.g. "500 g"
+	Price      float64
+	Categories []string // OFF category tags, e.g. "en:dairies"
+}
+
+// offProduct is the subset of an OFF JSONL line we read.
+type offProduct struct {
+	Code       string          `json:"code"`
+	Name       string          `json:"product_name"`
+	Brands     string          `json:"brands"`
+	Quantity   string          `json:"quantity"`
+	Price      json.RawMessage `json:"price"` // number or string in hand-made dumps
+	Categories []string        `json:"categories_tags"`
+}
+
+// defaultProductsPath returns $XDG_CONFIG_HOME/dashboard/products.jsonl (or the OS equivalent).
+func defaultProductsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dashboard", "products.jsonl")
+}
+
+// normalizeBarcode keeps the digits of a scanned code and drops leading
+// zeros, so a 12-digit UPC-A matches its 13-digit EAN form.
+func normalizeBarcode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimLeft(b.String(), "0")
+}
+
+// lookupProduct scans the dump at path for code.
+func lookupProduct(path, code string) (Product, error) {
+	want := normalizeBarcode(code)
+	if want == "" {
+		return Product{}, fmt.Errorf("invalid barcode %q", code)
+	}
+	if path == "" {
+		return Product{}, errors.New("no product database configured ([products] file)")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Product{}, fmt.Errorf("product database: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	name := path
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return Product{}, fmt.Errorf("product database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+		name = strings.TrimSuffix(name, ".gz")
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".tsv":
+		return lookupProductCSV(r, want)
+	}
+	return lookupProductJSONL(r, want)
+}
+
+func lookupProductJSONL(r io.Reader, want string) (Product, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // full OFF products have huge lines
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.Contains(line, []byte(want)) {
+			continue // cheap filter before decoding
+		}
+		var p offProduct
+		if json.Unmarshal(line, &p) != nil || normalizeBarcode(p.Code) != want {
+			continue
+		}
+		price, _ := strconv.ParseFloat(strings.Trim(string(p.Price), `"`), 64)
+		return Product{Code: p.Code, Name: p.Name, Brand: firstBrand(p.Brands), Quantity: p.Quantity, Price: price, Categories: p.Categories}, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return Product{}, fmt.Errorf("product database: %w", err)
+	}
+	return Product{}, errProductNotFound
+}
+
+func lookupProductCSV(r io.Reader, want string) (Product, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return Product{}, fmt.Errorf("product database: %w", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		col[h] = i
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+	if _, ok := col["code"]; !ok {
+		return Product{}, errors.New("product database: no \"code\" column")
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return Product{}, errProductNotFound
+		}
+		if err != nil {
+			continue // skip malformed rows, OFF exports have a few
+		}
+		if normalizeBarcode(field(rec, "code")) != want {
+			continue
+		}
+		price, _ := strconv.ParseFloat(field(rec, "price"), 64)
+		var cats []string
+		if tags := field(rec, "categories_tags"); tags != "" {
+			cats = strings.Split(tags, ",")
+		}
+		return Product{Code: field(rec, "code"), Name: field(rec, "product_name"), Brand: firstBrand(field(rec, "brands")),
+			Quantity: field(rec, "quantity"), Price: price, Categories: cats}, nil
+	}
+}
+
+func firstBrand(brands string) string {
+	b, _, _ := strings.Cut(brands, ",")
+	return strings.TrimSpace(b)
+}
+
+// displayName is the name for a new pantry item: the product name, falling
+// back to the brand.
+func (p Product) displayName() string {
+	if name := strings.TrimSpace(p.Name); name != "" {
+		return name
+	}
+	return p.Brand
+}
+
+// packaging reads the package quantity ("500 g", "1.5L") as a stock unit and
+// package size; ok is false for counts or multipacks ("6 x 330 ml").
+func (p Product) packaging() (string, float64, bool) {
+	ing := parseIngredient(p.Quantity)
+	unit, err := parseUnit(ing.Unit)
+	if err != nil || ing.Unit == "" || ing.Qty <= 0 || ing.Name != "" {
+		return "", 0, false
+	}
+	return unit, roundQty(ing.Qty), true
+}
+
+// offAisles maps OFF category tags to aisles; the first match wins.
+var offAisles = []struct{ tag, aisle string }{
+	{"en:frozen-foods", "frozen"},
+	{"en:fruits", "produce"}, {"en:vegetables", "produce"}, {"en:fresh-vegetables", "produce"},
+	{"en:breads", "bakery"}, {"en:pastries", "bakery"},
+	{"en:meats", "meat"}, {"en:fishes", "fish"}, {"en:seafood", "fish"},
+	{"en:dairies", "dairy"}, {"en:cheeses", "dairy"}, {"en:eggs", "dairy"},
+	{"en:beverages", "drinks"},
+}
+
+func (p Product) aisle() string {
+	for _, a := range offAisles {
+		for _, c := range p.Categories {
+			if strings.TrimSpace(c) == a.tag {
+				return a.aisle
+			}
+		}
+	}
+	return ""
+}
+
+// --- FORM ---
+type productLookupMsg struct {
+	code    string
+	product Product
+	err     error
+}
+
+func lookupProductCmd(path, code string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := lookupProduct(path, code)
+		return productLookupMsg{code, p, err}
+	}
+}
+
+// lookupBarcode handles Enter in the barcode field: a barcode already in the
+// pantry opens that item for editing, anything else is looked up in the
+// product database in the background.
+func (m *model) lookupBarcode() tea.Cmd {
+	code := strings.TrimSpace(m.inputs[foodBarcode].Value())
+	if code == "" {
+		return m.setFocus(foodName)
+	}
+	if m.editIndex < 0 {
+		for i, f := range m.foodItems {
+			if f.Barcode != "" && normalizeBarcode(f.Barcode) == normalizeBarcode(code) {
+				m.editIndex = i
+				m.initForm(stateAddFood, true)
+				m.formNote = "📦 Already in your pantry as " + f.Name + " — update the stock."
+				return m.setFocus(foodAmount)
+			}
+		}
+	}
+	m.formErr = ""
+	m.formNote = "🔍 Looking up " + code + "..."
+	return tea.Batch(m.setFocus(foodName), lookupProductCmd(m.cfg.Products.File, code))
+}
+
+// prefillProduct fills the food form from a looked-up product, leaving
+// anything already typed alone.
+func (m *model) prefillProduct(p Product) {
+	fill := func(i int, v string) {
+		if v != "" && strings.TrimSpace(m.inputs[i].Value()) == "" {
+			m.inputs[i].SetValue(v)
+			m.fieldEdited(i)
+		}
+	}
+	fill(foodName, p.displayName())
+	if p.Price > 0 {
+		fill(foodPrice, fmt.Sprintf("%.2f", p.Price))
+	}
+	if unit, size, ok := p.packaging(); ok {
+		fill(foodUnit, unit)
+		fill(foodPackSize, fmtQty(size))
+	}
+	fill(foodAisle, p.aisle())
+
+	m.formNote = "📦 Found: " + p.displayName()
+	if p.Brand != "" && p.Brand != p.displayName() {
+		m.formNote += " (" + p.Brand + ")"
+	}
+	if p.Quantity != "" {
+		m.formNote += ", " + p.Quantity
+	}
+}
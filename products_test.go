@@ -0,0 +1,157 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const productsJSONL = `{"code":"3017620422003","product_name":"Nutella","brands":"Ferrero, Nutella","quantity":"400 g","categories_tags":["en:spreads"]}
+not json at all
+{"code":"0041196910759","product_name":"Lentil soup","quantity":"540 ml","price":"2.49"}
+{"code":"5000112637922","product_name":"Cola","quantity":"6 x 330 ml","price":4.5,"categories_tags":["en:beverages"]}
+`
+
+const productsTSV = "code\tproduct_name\tbrands\tquantity\tprice\tcategories_tags\n" +
+	"3017620422003\tNutella\tFerrero\t400 g\t\ten:spreads,en:sweet-spreads\n" +
+	"short row\n" +
+	"041196910759\tLentil soup\t\t540 ml\t2.49\t\n"
+
+func TestLookupProductJSONL(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    Product
+		wantErr error
+	}{
+		{"first brand only", "3017620422003", Product{Code: "3017620422003", Name: "Nutella", Brand: "Ferrero", Quantity: "400 g", Categories: []string{"en:spreads"}}, nil},
+		{"UPC-A finds the EAN-13", "041196910759", Product{Code: "0041196910759", Name: "Lentil soup", Quantity: "540 ml", Price: 2.49}, nil},
+		{"numeric price", "5000112637922", Product{Code: "5000112637922", Name: "Cola", Quantity: "6 x 330 ml", Price: 4.5, Categories: []string{"en:beverages"}}, nil},
+		{"missing", "4006381333931", Product{}, errProductNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupProductJSONL(strings.NewReader(productsJSONL), normalizeBarcode(tt.code))
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, %v; want %+v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookupProductCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    Product
+		wantErr error
+	}{
+		{"category list", "3017620422003", Product{Code: "3017620422003", Name: "Nutella", Brand: "Ferrero", Quantity: "400 g", Categories: []string{"en:spreads", "en:sweet-spreads"}}, nil},
+		{"EAN-13 finds the UPC-A", "0041196910759", Product{Code: "041196910759", Name: "Lentil soup", Quantity: "540 ml", Price: 2.49}, nil},
+		{"missing", "4006381333931", Product{}, errProductNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupProductCSV(strings.NewReader(productsTSV), normalizeBarcode(tt.code))
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, %v; want %+v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := lookupProductCSV(strings.NewReader("name\tbrands\nNutella\tFerrero\n"), "3017620422003"); err == nil {
+		t.Error("dump without a code column was read")
+	}
+}
+
+func TestLookupProductFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, zip bool) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if !zip {
+			f.WriteString(content)
+			return path
+		}
+		gz := gzip.NewWriter(f)
+		gz.Write([]byte(content))
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"jsonl", write("products.jsonl", productsJSONL, false)},
+		{"gzipped jsonl", write("products.jsonl.gz", productsJSONL, true)},
+		{"tsv", write("products.tsv", productsTSV, false)},
+		{"gzipped csv", write("products.csv.gz", productsTSV, true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := lookupProduct(tt.path, "041196910759")
+			if err != nil || p.Name != "Lentil soup" || p.Price != 2.49 {
+				t.Errorf("got %+v, %v", p, err)
+			}
+		})
+	}
+
+	if _, err := lookupProduct(write("broken.jsonl.gz", productsJSONL, false), "3017620422003"); err == nil {
+		t.Error("file that is not gzip was read")
+	}
+	if _, err := lookupProduct(tests[0].path, "no digits"); err == nil {
+		t.Error("barcode without digits was looked up")
+	}
+	if _, err := lookupProduct("", "3017620422003"); err == nil {
+		t.Error("lookup without a database succeeded")
+	}
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"3017620422003", "3017620422003"},
+		{"041196910759", "41196910759"},  // UPC-A
+		{"0041196910759", "41196910759"}, // the same product as EAN-13
+		{" 4006-3813 3393 1 ", "4006381333931"},
+		{"ABC", ""},
+		{"0000", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeBarcode(tt.in); got != tt.want {
+			t.Errorf("normalizeBarcode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestProductPackaging(t *testing.T) {
+	tests := []struct {
+		quantity string
+		unit     string
+		size     float64
+		ok       bool
+	}{
+		{"500 g", "g", 500, true},
+		{"1.5L", "L", 1.5, true},
+		{"250ml", "ml", 250, true},
+		{"6 x 330 ml", "", 0, false},
+		{"12", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		unit, size, ok := Product{Quantity: tt.quantity}.packaging()
+		if unit != tt.unit || size != tt.size || ok != tt.ok {
+			t.Errorf("packaging(%q) = %q, %v, %v; want %q, %v, %v", tt.quantity, unit, size, ok, tt.unit, tt.size, tt.ok)
+		}
+	}
+}
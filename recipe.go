@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- RECIPE STREAMING ---
+// The LLM provider streams its reply. A goroutine reads it and hands each piece
+// to the UI through a channel; Update appends it and asks for the next one, so
+// the recipe box fills in live.
+type recipeStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	events chan tea.Msg
+}
+
+type recipeStartedMsg struct{ stream *recipeStream }
+
+type recipeChunkMsg struct {
+	stream *recipeStream
+	text   string
+}
+
+type recipeFailedMsg struct {
+	stream *recipeStream
+	err    error
+}
+
+type recipeDoneMsg struct {
+	stream  *recipeStream
+	elapsed time.Duration
+	tokens  int
+	evalDur time.Duration
+}
+
+// summary is the status line shown once the recipe is complete.
+func (d recipeDoneMsg) summary() string {
+	s := fmt.Sprintf("✅ Recipe ready in %.1fs", d.elapsed.Seconds())
+	if d.tokens > 0 {
+		s += fmt.Sprintf(" • %d tokens", d.tokens)
+		if d.evalDur > 0 {
+			s += fmt.Sprintf(" (%.1f tok/s)", float64(d.tokens)/d.evalDur.Seconds())
+		}
+	}
+	return s
+}
+
+// next waits for the stream's next event; a cancelled stream yields nothing.
+func (r *recipeStream) next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-r.events:
+			return msg
+		case <-r.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func recipePrompt(ingredients []string) string {
+	return fmt.Sprintf(
+		"You are an expert chef. Create a short, simple, and tasty recipe using ONLY these ingredients (you can assume I have basic pantry staples like salt, pepper, water, and cooking oil): %s.\n\n"+
+			"Please keep it concise so it fits on a terminal screen. Use this exact plain-text format:\n"+
+			"TITLE: [Name of Dish]\n\n"+
+			"INGREDIENTS:\n- [Item]\n\n"+
+			"INSTRUCTIONS:\n1. [Step 1]\n2. [Step 2]",
+		strings.Join(ingredients, ", "),
+	)
+}
+
+// --- PANTRY MODE ---
+// "Cook from what I have" builds the prompt from everything in stock instead
+// of the cart, listing the items that should be used up first.
+type pantryIngredient struct {
+	FoodItem
+	Priority int    // higher = use sooner; 0 = no rush
+	Reason   string // why it should be used soon
+}
+
+// pantryPriority ranks how urgently an item should be cooked.
+func pantryPriority(f FoodItem) (int, string) {
+	if b, ok := f.nextExpiry(); ok && b.Expires.daysUntil() >= 0 && b.Expires.daysUntil() <= expiringDays {
+		return 2, fmt.Sprintf("%s expiring %s", amountText(b.Qty, f.Unit), expiryText(b.Expires))
+	}
+	if f.RenewThreshold > 0 && f.Amount <= f.RenewThreshold {
+		return 1, "running low"
+	}
+	return 0, ""
+}
+
+// pantryIngredients returns every item in stock, most urgent first.
+func (m model) pantryIngredients() []pantryIngredient {
+	var out []pantryIngredient
+	for _, f := range m.foodItems {
+		if f.Amount <= 0 {
+			continue
+		}
+		p := pantryIngredient{FoodItem: f}
+		p.Priority, p.Reason = pantryPriority(f)
+		out = append(out, p)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Priority > out[j].Priority })
+	return out
+}
+
+func pantryRecipePrompt(items []pantryIngredient, servings int, diet string) string {
+	var list []string
+	for _, p := range items {
+		line := fmt.Sprintf("- %s (%s in stock)", p.Name, amountText(p.Amount, p.Unit))
+		if p.Priority > 0 {
+			line += " - USE FIRST, " + p.Reason
+		}
+		list = append(list, line)
+	}
+	constraints := ""
+	if diet != "" {
+		constraints = "The recipe MUST respect these dietary requirements: " + diet + ".\n"
+	}
+	return fmt.Sprintf(
+		"You are an expert chef. Suggest ONE short, simple, and tasty recipe for %d serving(s) using ingredients I already have at home. "+
+			"You do not need to use everything, but use the items marked USE FIRST wherever they fit. "+
+			"Do not add ingredients that are not in the list, apart from basic pantry staples like salt, pepper, water, and cooking oil.\n"+
+			"%s\nMy pantry:\n%s\n\n"+
+			"Please keep it concise so it fits on a terminal screen. Use this exact plain-text format:\n"+
+			"TITLE: [Name of Dish]\n\n"+
+			"INGREDIENTS:\n- [Quantity] [Item]\n\n"+
+			"INSTRUCTIONS:\n1. [Step 1]\n2. [Step 2]",
+		servings, constraints, strings.Join(list, "\n"),
+	)
+}
+
+// startPantryRecipe reads the options form and asks for a recipe from current stock.
+func (m *model) startPantryRecipe() (tea.Cmd, error) {
+	servings, err := strconv.Atoi(strings.TrimSpace(m.inputs[0].Value()))
+	if err != nil || servings < 1 || servings > 20 {
+		m.setFocus(0)
+		return nil, fmt.Errorf("servings must be a number from 1 to 20")
+	}
+	items := m.pantryIngredients()
+	if len(items) == 0 {
+		return nil, fmt.Errorf("nothing is in stock; add items or use the cart recipe ('r') instead")
+	}
+	m.recipeServings = servings
+	m.recipeDiet = strings.TrimSpace(m.inputs[1].Value())
+
+	m.state = stateFoodRecipe
+	m.recipeView.GotoTop()
+	m.setRecipe("⏳ Asking your AI chef what to cook from your pantry...")
+	return m.startRecipe(pantryRecipePrompt(items, m.recipeServings, m.recipeDiet)), nil
+}
+
+func (m model) viewPantryRecipeForm() string {
+	s := titleStyle.Render("🥘 COOK FROM WHAT I HAVE") + "\n\n"
+	for i := range m.inputs {
+		s += m.inputs[i].View() + "\n"
+	}
+
+	s += "\nIn stock:\n"
+	items := m.pantryIngredients()
+	if len(items) == 0 {
+		s += "    Nothing in stock.\n"
+	}
+	for _, p := range items {
+		line := fmt.Sprintf("    %s (%s)", p.Name, amountText(p.Amount, p.Unit))
+		if p.Priority > 0 {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("#E1B12C")).Render(line + " ⏰ use first: " + p.Reason)
+		}
+		s += line + "\n"
+	}
+
+	if m.formErr != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4C4C")).Render("❌ "+m.formErr)
+	}
+	s += "\n\n" + hintStyle.Render("[Tab/Up/Down: Next • Enter: Generate • Esc: Cancel]")
+	return s
+}
+
+// startRecipe begins streaming a recipe for the given prompt.
+func (m *model) startRecipe(prompt string) tea.Cmd {
+	m.stopRecipe()
+	ctx, cancel := context.WithCancel(m.ctx)
+	stream := &recipeStream{ctx: ctx, cancel: cancel, events: make(chan tea.Msg)}
+	m.recipeStream = stream
+	m.isGenerating = true
+	m.recipeReady = false
+	m.recipeCooked = false
+	m.recipeCookAgain = false
+	m.recipeOpen = ""
+	return generateRecipeCmd(m.llm, prompt, stream)
+}
+
+// stopRecipe aborts the in-flight request, if any. Late events from it are ignored.
+func (m *model) stopRecipe() {
+	if m.recipeStream != nil {
+		m.recipeStream.cancel()
+		m.recipeStream = nil
+	}
+	m.isGenerating = false
+}
+
+// generateRecipeCmd sends the prompt and, once the provider answers, starts
+// pumping the streamed reply into stream.events.
+func generateRecipeCmd(llm LLMProvider, prompt string, stream *recipeStream) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		reply, err := llm.Chat(stream.ctx, prompt)
+		if err != nil {
+			return recipeFailedMsg{stream, err}
+		}
+		go pumpRecipe(reply, start, stream)
+		return recipeStartedMsg{stream}
+	}
+}
+
+// pumpRecipe forwards the reply piece by piece until it ends, fails, or the
+// user cancels.
+func pumpRecipe(reply LLMStream, start time.Time, stream *recipeStream) {
+	defer reply.Close()
+
+	send := func(msg tea.Msg) bool {
+		select {
+		case stream.events <- msg:
+			return true
+		case <-stream.ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		text, err := reply.Next()
+		if err == io.EOF {
+			stats := reply.Stats()
+			send(recipeDoneMsg{
+				stream:  stream,
+				elapsed: time.Since(start),
+				tokens:  stats.Tokens,
+				evalDur: stats.EvalDuration,
+			})
+			return
+		}
+		if err != nil {
+			send(recipeFailedMsg{stream, err})
+			return
+		}
+		if !send(recipeChunkMsg{stream, text}) {
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamingModel is on the recipe screen with a stream that has sent "partial".
+func streamingModel() model {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), nil, fakeLLMProvider{}, &localStore{}, "u")
+	m.state = stateFoodRecipe
+	m.startRecipe("prompt")
+	m.setRecipe("TITLE: Omelette\nINGREDIENTS:\n- 2 eg")
+	return m
+}
+
+func TestEscKeepsPartialRecipe(t *testing.T) {
+	m := streamingModel()
+	if !strings.Contains(m.recipeView.View(), "▌") {
+		t.Fatal("no streaming cursor while generating")
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(model)
+	if m.isGenerating || m.state != stateFoodRecipe {
+		t.Fatalf("generating %v on state %v", m.isGenerating, m.state)
+	}
+	if strings.Contains(m.recipeView.View(), "▌") {
+		t.Error("streaming cursor left after cancelling")
+	}
+	if !strings.Contains(m.recipeView.View(), "Omelette") {
+		t.Error("partial recipe dropped")
+	}
+}
+
+func TestLeavingRecipeStopsStream(t *testing.T) {
+	m := streamingModel()
+	stream := m.recipeStream
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = next.(model)
+	if m.state != stateFood {
+		t.Fatalf("state = %v, want the food screen", m.state)
+	}
+	if m.recipeStream != nil || m.isGenerating || stream.ctx.Err() == nil {
+		t.Error("stream still running after leaving the recipe screen")
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- RECIPE BOOK ---
+// Saved recipes live in the "Recipes" category. Each one is parsed from the
+// TITLE/INGREDIENTS/INSTRUCTIONS layout the prompt asks for; the raw answer is
+// kept too in case the model strayed from it.
+type Recipe struct {
+	ID           string    `json:"id,omitempty"`
+	Title        string    `json:"title"`
+	Ingredients  []string  `json:"ingredients"`
+	Instructions []string  `json:"instructions"`
+	Text         string    `json:"text"`
+	SavedAt      time.Time `json:"savedAt"`
+	CookedAt     time.Time `json:"cookedAt,omitempty"` // last time it was taken out of stock
+}
+
+func (r *Recipe) itemID() *string  { return &r.ID }
+func (r *Recipe) itemName() string { return r.Title }
+
+// parseRecipe splits a generated recipe into its sections. Markdown emphasis,
+// bullets and step numbers are stripped; a missing TITLE falls back to the
+// first line.
+func parseRecipe(text string) Recipe {
+	r := Recipe{Text: strings.TrimSpace(text)}
+	section := ""
+	var firstLine string
+
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(strings.Trim(strings.TrimSpace(raw), "*#_ "))
+		if line == "" {
+			continue
+		}
+		if firstLine == "" {
+			firstLine = line
+		}
+
+		if header, rest, ok := recipeHeader(line); ok {
+			section = header
+			line = rest
+			if line == "" {
+				continue
+			}
+		}
+
+		switch section {
+		case "TITLE":
+			if r.Title == "" {
+				r.Title = line
+			}
+		case "INGREDIENTS":
+			r.Ingredients = append(r.Ingredients, stripBullet(line))
+		case "INSTRUCTIONS":
+			r.Instructions = append(r.Instructions, stripStepNumber(stripBullet(line)))
+		}
+	}
+
+	if r.Title == "" {
+		r.Title = firstLine
+	}
+	if r.Title == "" {
+		r.Title = "Untitled recipe"
+	}
+	return r
+}
+
+// recipeHeader recognises "TITLE:", "INGREDIENTS:" and "INSTRUCTIONS:" (or
+// STEPS/DIRECTIONS) at the start of line, returning the text after the colon.
+func recipeHeader(line string) (string, string, bool) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	rest = strings.TrimSpace(strings.Trim(rest, "*_ "))
+	switch strings.ToUpper(strings.Trim(name, "*_ ")) {
+	case "TITLE":
+		return "TITLE", rest, true
+	case "INGREDIENTS":
+		return "INGREDIENTS", rest, true
+	case "INSTRUCTIONS", "STEPS", "DIRECTIONS", "METHOD":
+		return "INSTRUCTIONS", rest, true
+	}
+	return "", "", false
+}
+
+func stripBullet(line string) string {
+	for _, b := range []string{"- ", "* ", "• ", "+ "} {
+		if strings.HasPrefix(line, b) {
+			return strings.TrimSpace(line[len(b):])
+		}
+	}
+	return line
+}
+
+// stripStepNumber removes a leading "1." or "1)" from an instruction.
+func stripStepNumber(line string) string {
+	i := 0
+	for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+		i++
+	}
+	if i > 0 && i < len(line) && (line[i] == '.' || line[i] == ')') {
+		return strings.TrimSpace(line[i+1:])
+	}
+	return line
+}
+
+// format renders a saved recipe back into the prompt's plain-text layout.
+func (r Recipe) format() string {
+	if len(r.Ingredients) == 0 && len(r.Instructions) == 0 {
+		return r.Text
+	}
+	s := "TITLE: " + r.Title + "\n\nINGREDIENTS:\n"
+	for _, ing := range r.Ingredients {
+		s += "- " + ing + "\n"
+	}
+	s += "\nINSTRUCTIONS:\n"
+	for i, step := range r.Instructions {
+		s += fmt.Sprintf("%d. %s\n", i+1, step)
+	}
+	return strings.TrimSpace(s)
+}
+
+// saveRecipe stores the recipe currently on screen in the recipe book.
+func (m *model) saveRecipe() tea.Cmd {
+	if !m.recipeReady {
+		if m.isGenerating {
+			m.statusMsg = "Wait for the recipe to finish before saving."
+		} else if m.recipeOpen != "" {
+			m.statusMsg = "Already in your recipe book."
+		} else {
+			m.statusMsg = "Nothing to save."
+		}
+		return nil
+	}
+	r := parseRecipe(m.generatedRecipe)
+	r.ID = newItemID()
+	r.SavedAt = time.Now()
+	if m.recipeCooked {
+		r.CookedAt = r.SavedAt
+	}
+	m.recipes = append(m.recipes, r)
+	m.recipeReady = false // saved once; a second 's' would only duplicate it
+	m.recipeOpen = r.ID
+	m.statusMsg = "📖 Saved \"" + r.Title + "\" to your recipe book"
+	return m.queueSync("Recipes", m.recipes)
+}
+
+// openRecipe shows a saved recipe in the scrolling recipe view.
+func (m *model) openRecipe(i int) {
+	m.state = stateViewRecipe
+	m.recipeReady = false
+	m.recipeCooked = !m.recipes[i].CookedAt.IsZero()
+	m.recipeCookAgain = false
+	m.recipeOpen = m.recipes[i].ID
+	m.recipeView.GotoTop()
+	m.setRecipe(m.recipes[i].format())
+}
+
+// --- VIEW ---
+func (m model) viewRecipeBook() string {
+	s := titleStyle.Render("📖 RECIPE BOOK") + "\n"
+	if len(m.recipes) == 0 {
+		s += "    No saved recipes. Generate one with 'r' on the Food screen and press 's' to save it.\n"
+	}
+	start, end := listWindow(m.cursor, len(m.recipes), m.listRows(8))
+	for i := start; i < end; i++ {
+		r := m.recipes[i]
+		cursor := "  "
+		if m.cursor == i {
+			cursor = "▶ "
+		}
+		titleCol := lipgloss.NewStyle().Width(32).Render(r.Title)
+		line := fmt.Sprintf("  %s %s  %2d ingredient(s)  %s", cursor, titleCol, len(r.Ingredients), r.SavedAt.Local().Format("Jan 02, 2006"))
+		if m.cursor == i {
+			s += selStyle.Render(line) + "\n"
+		} else {
+			s += itemStyle.Render(line) + "\n"
+		}
+	}
+	s += pageIndicator(start, end, len(m.recipes))
+	s += "\n" + hintStyle.Render("[Enter: Open • d: Delete • up/down: Navigate • Esc: Back]")
+	s += "\n" + m.renderStatus()
+	return s
+}
+
+func (m model) viewSavedRecipe() string {
+	s := titleStyle.Render("📖 SAVED RECIPE") + "\n\n"
+	s += m.viewRecipeBox()
+	s += "\n\n" + hintStyle.Render("[up/down/pgup/pgdn: Scroll • m: Add missing to cart • c: Cooked it • Esc: Back to Recipe Book]")
+	s += "\n" + m.renderStatus()
+	return s
+}
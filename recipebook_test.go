@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRecipe(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		title string
+		ings  []string
+		steps []string
+	}{
+		{
+			name:  "prompt layout",
+			text:  fakeRecipe,
+			title: "Pantry Omelette",
+			ings:  []string{"3 eggs", "50g cheese", "1 tomato", "Salt and pepper to taste"},
+			steps: []string{
+				"Whisk the eggs with a pinch of salt and pepper.",
+				"Pour into a hot oiled pan and cook for 2 minutes.",
+				"Add the cheese and sliced tomato, fold and serve.",
+			},
+		},
+		{
+			name:  "markdown and other headers",
+			text:  "**Title:** Toast\n\n## Ingredients:\n* 2 slices bread\n• butter\n\n### Steps:\n1) Toast the bread.\n2. Butter it.",
+			title: "Toast",
+			ings:  []string{"2 slices bread", "butter"},
+			steps: []string{"Toast the bread.", "Butter it."},
+		},
+		{
+			name:  "no title falls back to the first line",
+			text:  "Quick rice\nINGREDIENTS: 1 cup rice\nMETHOD:\n- Boil it",
+			title: "Quick rice",
+			ings:  []string{"1 cup rice"},
+			steps: []string{"Boil it"},
+		},
+		{
+			name:  "empty answer",
+			text:  "  \n",
+			title: "Untitled recipe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseRecipe(tt.text)
+			if r.Title != tt.title {
+				t.Errorf("title = %q, want %q", r.Title, tt.title)
+			}
+			if !reflect.DeepEqual(r.Ingredients, tt.ings) {
+				t.Errorf("ingredients = %q, want %q", r.Ingredients, tt.ings)
+			}
+			if !reflect.DeepEqual(r.Instructions, tt.steps) {
+				t.Errorf("instructions = %q, want %q", r.Instructions, tt.steps)
+			}
+		})
+	}
+}
+
+func TestFakeLLMStreamsRecipe(t *testing.T) {
+	stream, err := fakeLLMProvider{}.Chat(context.Background(), "anything")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var b strings.Builder
+	for {
+		piece, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.WriteString(piece)
+	}
+	if b.String() != fakeRecipe {
+		t.Errorf("streamed %q", b.String())
+	}
+	if got := parseRecipe(b.String()).format(); parseRecipe(got).Title != "Pantry Omelette" {
+		t.Errorf("format does not round-trip: %q", got)
+	}
+}
+
+func TestCookSavedRecipeTwice(t *testing.T) {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), nil, fakeLLMProvider{}, &localStore{}, "u")
+	m.foodItems = []FoodItem{{ID: "e", Name: "Eggs", Amount: 6}}
+	r := parseRecipe(fakeRecipe)
+	r.ID = "r"
+	m.recipes = []Recipe{r}
+
+	m.openRecipe(0)
+	m.cookRecipe()
+	if m.foodItems[0].Amount != 3 || m.recipes[0].CookedAt.IsZero() {
+		t.Fatalf("after cooking: %v eggs, cooked at %v", m.foodItems[0].Amount, m.recipes[0].CookedAt)
+	}
+
+	// Reopening must not let the same meal be taken out of stock silently
+	m.openRecipe(0)
+	m.cookRecipe()
+	if m.foodItems[0].Amount != 3 {
+		t.Fatalf("cooked again without confirming: %v eggs left", m.foodItems[0].Amount)
+	}
+	if !strings.Contains(m.statusMsg, "Already cooked") {
+		t.Errorf("status = %q", m.statusMsg)
+	}
+	m.cookRecipe()
+	if m.foodItems[0].Amount != 0 {
+		t.Errorf("confirmed cook left %v eggs", m.foodItems[0].Amount)
+	}
+}
+
+func TestCookNeedsFinishedRecipe(t *testing.T) {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), nil, fakeLLMProvider{}, &localStore{}, "u")
+	m.foodItems = []FoodItem{{ID: "e", Name: "Eggs", Amount: 6}}
+	m.startRecipe("prompt")
+	m.setRecipe("INGREDIENTS:\n- 3 eggs\n- 1 tomato")
+	m.stopRecipe()
+
+	if cmd := m.cookRecipe(); cmd != nil || m.foodItems[0].Amount != 6 {
+		t.Fatalf("cooked a cancelled recipe: %v eggs left", m.foodItems[0].Amount)
+	}
+	m.recipeReady = true
+	m.cookRecipe()
+	if m.foodItems[0].Amount != 3 {
+		t.Errorf("finished recipe left %v eggs", m.foodItems[0].Amount)
+	}
+}
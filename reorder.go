@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- REORDER RULES ---
+// Each FoodItem with a renew threshold carries a reorder rule. Whenever stock
+// changes (edit, cook, delivery) the rules are evaluated: items at or below
+// their threshold are put in the cart, or gathered into a pending order that
+// waits for confirmation on the Orders screen. Stock itself only goes up when
+// an order is delivered. A rule fires once per shortage: it stays quiet until
+// stock is back above the threshold or the order it was waiting on finished.
+const (
+	reorderToCart  = "cart"  // add to the cart for the next checkout
+	reorderToOrder = "order" // propose an order, placed once confirmed
+
+	deliveryModeDelivery = "delivery"
+	deliveryModePickup   = "pickup"
+
+	defaultReorderQty = 3 // cart steps (see cartStep), e.g. 3 pieces or 3 packages
+)
+
+// reorderChoices are the options of the "When low" row in the food form.
+var reorderChoices = []struct {
+	label, action, delivery string
+}{
+	{"🛒 Add to cart", reorderToCart, ""},
+	{"🚚 Order (delivery)", reorderToOrder, deliveryModeDelivery},
+	{"🏪 Order (pick up)", reorderToOrder, deliveryModePickup},
+}
+
+func reorderChoiceIndex(f FoodItem) int {
+	for i, c := range reorderChoices {
+		if c.action == f.reorderAction() && (c.delivery == "" || c.delivery == f.reorderDelivery()) {
+			return i
+		}
+	}
+	return 0
+}
+
+func (f FoodItem) reorderQty() float64 {
+	if f.ReorderQty > 0 {
+		return f.ReorderQty
+	}
+	return defaultReorderQty * f.cartStep()
+}
+
+func (f FoodItem) reorderAction() string {
+	if f.ReorderAction == reorderToOrder {
+		return reorderToOrder
+	}
+	return reorderToCart
+}
+
+func (f FoodItem) reorderDelivery() string {
+	if f.ReorderDelivery == deliveryModePickup {
+		return deliveryModePickup
+	}
+	return deliveryModeDelivery
+}
+
+// needsReorder reports whether the item's rule fires: a threshold is set and
+// stock has dropped to or below it.
+func (f FoodItem) needsReorder() bool {
+	return f.RenewThreshold > 0 && f.Amount <= f.RenewThreshold
+}
+
+// onTheWay reports whether the item is already in the cart, an open order or
+// a pending reorder, so a rule does not fire twice for the same shortage.
+func (m model) onTheWay(f FoodItem) bool {
+	if f.CartQty > 0 {
+		return true
+	}
+	for _, o := range m.store.Orders {
+		if o.finished() {
+			continue
+		}
+		for _, l := range o.Lines {
+			if l.ItemID == f.ID {
+				return true
+			}
+		}
+	}
+	for _, r := range m.store.Reorders {
+		for _, l := range r.Lines {
+			if l.ItemID == f.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyReorderRules evaluates every rule and returns a status line describing
+// what fired, or "" when nothing did.
+func (m *model) applyReorderRules() string {
+	if m.store.Reordered == nil {
+		m.store.Reordered = make(map[string]bool)
+	}
+	var carted, proposed []string
+	changed := false
+	for i := range m.foodItems {
+		f := m.foodItems[i]
+		if !f.needsReorder() {
+			if m.store.Reordered[f.ID] {
+				delete(m.store.Reordered, f.ID)
+				changed = true
+			}
+			continue
+		}
+		if m.store.Reordered[f.ID] {
+			continue
+		}
+		m.store.Reordered[f.ID] = true
+		changed = true
+		if m.onTheWay(f) {
+			continue
+		}
+		line := qtyLabel(f.reorderQty(), f.Unit) + " " + f.Name
+		if f.reorderAction() == reorderToOrder {
+			m.proposeReorder(f)
+			proposed = append(proposed, line)
+		} else {
+			m.foodItems[i].CartQty = f.reorderQty()
+			carted = append(carted, line)
+		}
+	}
+
+	var parts []string
+	if len(carted) > 0 {
+		parts = append(parts, "added "+strings.Join(carted, ", ")+" to the cart")
+	}
+	if changed {
+		m.saveStore()
+	}
+	if len(proposed) > 0 {
+		parts = append(parts, "proposed an order for "+strings.Join(proposed, ", ")+" (confirm in Orders)")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "🔁 Reorder: " + strings.Join(parts, "; ")
+}
+
+// proposeReorder adds the item to the pending order for its delivery mode.
+func (m *model) proposeReorder(f FoodItem) {
+	line := OrderLine{ItemID: f.ID, Name: f.Name, Qty: f.reorderQty(), Unit: f.Unit, UnitPrice: f.unitPrice()}
+	d := m.reorderOption(f.reorderDelivery())
+	for i := range m.store.Reorders {
+		if r := &m.store.Reorders[i]; r.Delivery == d.label() {
+			r.Lines = append(r.Lines, line)
+			r.DeliveryFee = d.feeAt(Store{}, linesTotal(r.Lines))
+			return
+		}
+	}
+	m.store.Reorders = append(m.store.Reorders, OrderRequest{
+		UserID:      m.token,
+		Lines:       []OrderLine{line},
+		Delivery:    d.label(),
+		DeliveryFee: d.feeAt(Store{}, linesTotal([]OrderLine{line})),
+	})
+}
+
+// reorderFailedMsg puts a reorder back in the queue when placing it failed.
+type reorderFailedMsg struct {
+	req OrderRequest
+	err error
+}
+
+// confirmReorder places the first pending reorder.
+func (m *model) confirmReorder() tea.Cmd {
+	if len(m.store.Reorders) == 0 {
+		m.statusMsg = "No reorder awaiting confirmation."
+		return nil
+	}
+	req := m.store.Reorders[0]
+	m.store.Reorders = m.store.Reorders[1:]
+	m.saveStore()
+	m.statusMsg = "⏳ Placing reorder..."
+	place := placeOrderCmd(m.ctx, m.orderProvider, req)
+	return func() tea.Msg {
+		msg := place()
+		if e, ok := msg.(errMsg); ok {
+			return reorderFailedMsg{req, e.err}
+		}
+		return msg
+	}
+}
+
+// dismissReorder drops the first pending reorder without ordering.
+func (m *model) dismissReorder() {
+	if len(m.store.Reorders) == 0 {
+		return
+	}
+	m.store.Reorders = m.store.Reorders[1:]
+	m.saveStore()
+	m.statusMsg = "Reorder dismissed."
+}
+
+// --- VIEW ---
+
+// viewPendingReorder renders the first reorder awaiting confirmation, or "".
+func (m model) viewPendingReorder() string {
+	if len(m.store.Reorders) == 0 {
+		return ""
+	}
+	r := m.store.Reorders[0]
+	detail := lipgloss.NewStyle().Bold(true).Render("🔁 Reorder awaiting confirmation") + "\n"
+	for _, l := range r.Lines {
+		detail += fmt.Sprintf("%-8s %-15s = $%.2f\n", qtyLabel(l.Qty, l.Unit), l.Name, l.UnitPrice*l.Qty)
+	}
+	detail += fmt.Sprintf("%s • Total: $%.2f", r.Delivery, linesTotal(r.Lines)+r.DeliveryFee)
+	if more := len(m.store.Reorders) - 1; more > 0 {
+		detail += hintStyle.Render(fmt.Sprintf("\n(+%d more pending)", more))
+	}
+	detail += "\n" + hintStyle.Render("[y: Place order • n: Dismiss]")
+	return boxStyle.Copy().BorderForeground(lipgloss.Color("#E1B12C")).Render(detail) + "\n"
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tui/client"
+)
+
+// reorderModel loads a pantry of low eggs (cart rule), fine milk and empty
+// rice (order rule) from store, as after a start.
+func reorderModel(t *testing.T, store *localStore) (model, []client.Category) {
+	t.Helper()
+	items := []FoodItem{
+		{ID: "e", Name: "Eggs", Amount: 1, RenewThreshold: 2, ReorderQty: 6},
+		{ID: "m", Name: "Milk", Amount: 3, RenewThreshold: 1},
+		{ID: "r", Name: "Rice", Amount: 0, RenewThreshold: 1, ReorderAction: reorderToOrder},
+	}
+	content, _ := json.Marshal(map[string]any{"items": items})
+	cats := []client.Category{{Id: "f", Name: "Food", Content: content, Version: 1}}
+	if store.Categories == nil {
+		store.Categories = cats
+	}
+	return initialModel(context.Background(), Config{}, newFakeAPI(), nil, nil, store, "u"), cats
+}
+
+func fetch(m model, cats []client.Category) model {
+	next, _ := m.Update(dataFetchedMsg(cats))
+	return next.(model)
+}
+
+func TestReorderRulesFireOncePerShortage(t *testing.T) {
+	m, cats := reorderModel(t, &localStore{})
+	if m.foodItems[0].CartQty != 0 || len(m.store.Reorders) != 0 {
+		t.Fatal("rules fired on load")
+	}
+
+	// A stock change (here: editing milk) evaluates every rule
+	m.editIndex = 1
+	m.putFoodItem(FoodItem{Name: "Milk", Amount: 3, RenewThreshold: 1})
+	if m.foodItems[0].CartQty != 6 || len(m.store.Reorders) != 1 {
+		t.Fatalf("eggs %v in cart, %d reorders", m.foodItems[0].CartQty, len(m.store.Reorders))
+	}
+
+	m.statusMsg = "hello"
+	m = fetch(m, cats)
+	if strings.HasPrefix(m.statusMsg, "🔁") {
+		t.Errorf("fetch overwrote the status with %q", m.statusMsg)
+	}
+	if m.foodItems[0].CartQty != 6 || len(m.store.Reorders) != 1 {
+		t.Errorf("after fetch: eggs %v in cart, %d reorders", m.foodItems[0].CartQty, len(m.store.Reorders))
+	}
+}
+
+func TestDismissedReorderStaysDismissed(t *testing.T) {
+	m, cats := reorderModel(t, &localStore{})
+	m.applyReorderRules()
+	m.dismissReorder()
+
+	m = fetch(m, cats)
+	m.applyReorderRules() // another stock change
+	if len(m.store.Reorders) != 0 {
+		t.Fatalf("dismissed reorder proposed again: %+v", m.store.Reorders)
+	}
+
+	// Once rice is restocked the next shortage fires again
+	m.foodItems[2].Amount = 5
+	m.applyReorderRules()
+	m.foodItems[2].Amount = 0
+	m.applyReorderRules()
+	if len(m.store.Reorders) != 1 {
+		t.Errorf("new shortage proposed %d reorders", len(m.store.Reorders))
+	}
+}
+
+func TestUncartedItemStaysOut(t *testing.T) {
+	m, cats := reorderModel(t, &localStore{})
+	m.applyReorderRules()
+	m.foodItems[0].CartQty = 0 // taken out of the cart
+	m.saveStore()
+
+	m = fetch(m, cats)
+	m.applyReorderRules()
+	if m.foodItems[0].CartQty != 0 {
+		t.Errorf("eggs put back in the cart: %v", m.foodItems[0].CartQty)
+	}
+}
+
+func TestCartSurvivesRestart(t *testing.T) {
+	store := &localStore{}
+	m, cats := reorderModel(t, store)
+	m.applyReorderRules()
+	m.foodItems[1].CartQty = 2 // added by hand
+	m.saveStore()
+
+	m, _ = reorderModel(t, store)
+	if m.foodItems[0].CartQty != 6 || m.foodItems[1].CartQty != 2 {
+		t.Fatalf("cart after restart: eggs %v, milk %v", m.foodItems[0].CartQty, m.foodItems[1].CartQty)
+	}
+	m = fetch(m, cats)
+	if m.foodItems[0].CartQty != 6 || m.foodItems[1].CartQty != 2 {
+		t.Errorf("cart after fetch: eggs %v, milk %v", m.foodItems[0].CartQty, m.foodItems[1].CartQty)
+	}
+}
+
+func TestCancelledOrderRearmsRule(t *testing.T) {
+	m, _ := reorderModel(t, &localStore{})
+	m.applyReorderRules()
+	req := m.store.Reorders[0]
+	m.store.Reorders = nil
+	m.trackOrder(Order{ID: "o1", Status: orderPending, Lines: req.Lines})
+	m.trackOrder(Order{ID: "o1", Status: orderCancelled})
+	if len(m.store.Reorders) != 1 {
+		t.Errorf("cancelled rice not proposed again: %d reorders", len(m.store.Reorders))
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- STORES ---
+// Checkout compares what the cart costs at each store. Price lists come from
+// the "Stores" category, or from a local JSON file when the category is empty;
+// with neither, the cart is priced at the items' own prices. Delivery is
+// charged per the chosen delivery option (see delivery.go), unless the store
+// sets its own fee. A store price is in the same terms as FoodItem.Price: per unit
+// of stock, or per package for items sold in packages.
+type Store struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	DeliveryFee *float64           `json:"deliveryFee,omitempty"` // replaces the delivery option's fee; nil keeps it
+	MinOrder    float64            `json:"minOrder,omitempty"`    // for delivery; pickups have none
+	Prices      map[string]float64 `json:"prices"`                // item name (any case) or barcode -> price
+}
+
+func (s *Store) itemID() *string  { return &s.ID }
+func (s *Store) itemName() string { return s.Name }
+
+// maxSplitStores caps how many stores one split checkout may use.
+const maxSplitStores = 3
+
+// defaultStore prices the cart when no price lists are configured.
+func defaultStore() Store {
+	return Store{Name: "Usual store"}
+}
+
+// defaultStoresPath returns $XDG_CONFIG_HOME/dashboard/stores.json (or the OS equivalent).
+func defaultStoresPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dashboard", "stores.json")
+}
+
+// loadStoresFile reads price lists saved the way the category stores them
+// ({"items": [...]}) or as a bare array. A missing file is not an error.
+func loadStoresFile(path string) ([]Store, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stores file: %w", err)
+	}
+	var stores []Store
+	if err := json.Unmarshal(data, &stores); err != nil {
+		var wrapper struct {
+			Items []Store `json:"items"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("stores file %s: %w", path, err)
+		}
+		stores = wrapper.Items
+	}
+	ensureIDs(stores)
+	return stores, nil
+}
+
+// storeList is the stores checkout compares: the category, else the local file, else the default.
+func (m model) storeList() []Store {
+	switch {
+	case len(m.stores) > 0:
+		return m.stores
+	case len(m.fileStores) > 0:
+		return m.fileStores
+	}
+	return []Store{defaultStore()}
+}
+
+// price is what the store charges for f, in f's own terms; the default
+// store (no price list) charges the item's price.
+func (s Store) price(f FoodItem) (float64, bool) {
+	if s.Prices == nil {
+		return f.Price, true
+	}
+	if f.Barcode != "" {
+		for k, p := range s.Prices {
+			if normalizeBarcode(k) != "" && normalizeBarcode(k) == normalizeBarcode(f.Barcode) {
+				return p, true
+			}
+		}
+	}
+	for k, p := range s.Prices {
+		if strings.EqualFold(strings.TrimSpace(k), strings.TrimSpace(f.Name)) {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// unitPrice is the store's price for one unit of f's stock.
+func (s Store) unitPrice(f FoodItem) (float64, bool) {
+	p, ok := s.price(f)
+	if ok && f.PackSize > 0 {
+		p /= f.PackSize
+	}
+	return p, ok
+}
+
+// --- QUOTES ---
+
+// storeQuote is what (part of) the cart costs at one store.
+type storeQuote struct {
+	Store    Store
+	Lines    []OrderLine
+	Missing  []string // cart items the store does not sell
+	Subtotal float64
+	Fee      float64
+	Short    float64 // how far the subtotal is below the store's minimum order
+}
+
+func (q storeQuote) total() float64 { return q.Subtotal + q.Fee }
+
+// problem says why the quote cannot be ordered, or "" when it can.
+func (q storeQuote) problem() string {
+	switch {
+	case len(q.Missing) > 0:
+		return "no " + strings.Join(q.Missing, ", ")
+	case q.Short > 0:
+		return fmt.Sprintf("$%.2f below the $%.2f minimum", q.Short, q.Store.MinOrder)
+	}
+	return ""
+}
+
+// quoteStore prices items at s, delivered (or picked up) as d.
+func quoteStore(s Store, items []FoodItem, d DeliveryOption) storeQuote {
+	q := storeQuote{Store: s}
+	for _, f := range items {
+		p, ok := s.unitPrice(f)
+		if !ok {
+			q.Missing = append(q.Missing, f.Name)
+			continue
+		}
+		q.Lines = append(q.Lines, OrderLine{ItemID: f.ID, Name: f.Name, Qty: f.CartQty, Unit: f.Unit, UnitPrice: p})
+		q.Subtotal += p * f.CartQty
+	}
+	q.Fee = d.feeAt(s, q.Subtotal)
+	if !d.Pickup && s.MinOrder > q.Subtotal {
+		q.Short = s.MinOrder - q.Subtotal
+	}
+	return q
+}
+
+// checkoutOption is one row of the checkout: a single store, or the cart
+// split across several.
+type checkoutOption struct {
+	Quotes []storeQuote
+	Split  bool
+}
+
+func (o checkoutOption) total() float64 {
+	var t float64
+	for _, q := range o.Quotes {
+		t += q.total()
+	}
+	return t
+}
+
+func (o checkoutOption) problem() string {
+	for _, q := range o.Quotes {
+		if p := q.problem(); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+func (m model) cartItems() []FoodItem {
+	var items []FoodItem
+	for _, f := range m.foodItems {
+		if f.CartQty > 0 {
+			items = append(items, f)
+		}
+	}
+	return items
+}
+
+// checkoutOptions prices the cart at every store, cheapest orderable first,
+// and adds a split when spreading the cart over stores beats them all.
+func (m model) checkoutOptions() []checkoutOption {
+	items := m.cartItems()
+	if len(items) == 0 {
+		return nil
+	}
+	delivery := m.selectedDelivery()
+	stores := m.storeList()
+	var opts []checkoutOption
+	for _, s := range stores {
+		opts = append(opts, checkoutOption{Quotes: []storeQuote{quoteStore(s, items, delivery)}})
+	}
+	sort.SliceStable(opts, func(i, j int) bool {
+		pi, pj := opts[i].problem() == "", opts[j].problem() == ""
+		if pi != pj {
+			return pi
+		}
+		return opts[i].total() < opts[j].total()
+	})
+	if split, ok := splitCart(stores, items, delivery); ok && (opts[0].problem() != "" || split.total() < opts[0].total()-0.005) {
+		opts = append([]checkoutOption{split}, opts...)
+	}
+	return opts
+}
+
+// splitCart finds the cheapest orderable way to buy items from two or more
+// stores: every combination of up to maxSplitStores stores is tried, each
+// item going to the cheapest store of the combination.
+func splitCart(stores []Store, items []FoodItem, delivery DeliveryOption) (checkoutOption, bool) {
+	best := checkoutOption{Split: true}
+	bestTotal := math.Inf(1)
+
+	var try func(start int, picked []int)
+	try = func(start int, picked []int) {
+		if len(picked) >= 2 {
+			if opt, ok := assignCheapest(stores, picked, items, delivery); ok && opt.total() < bestTotal {
+				best, bestTotal = opt, opt.total()
+			}
+		}
+		if len(picked) == maxSplitStores {
+			return
+		}
+		for i := start; i < len(stores); i++ {
+			try(i+1, append(picked, i))
+		}
+	}
+	try(0, nil)
+	return best, !math.IsInf(bestTotal, 1)
+}
+
+// assignCheapest gives every item to its cheapest store among picked. It
+// fails when an item is sold by none of them, a store ends up with nothing
+// to order, or a delivery falls short of its minimum.
+func assignCheapest(stores []Store, picked []int, items []FoodItem, delivery DeliveryOption) (checkoutOption, bool) {
+	groups := make([][]FoodItem, len(picked))
+	for _, f := range items {
+		at, cheapest := -1, math.Inf(1)
+		for gi, si := range picked {
+			if p, ok := stores[si].unitPrice(f); ok && p < cheapest {
+				at, cheapest = gi, p
+			}
+		}
+		if at < 0 {
+			return checkoutOption{}, false
+		}
+		groups[at] = append(groups[at], f)
+	}
+	opt := checkoutOption{Split: true}
+	for gi, si := range picked {
+		if len(groups[gi]) == 0 {
+			return checkoutOption{}, false
+		}
+		q := quoteStore(stores[si], groups[gi], delivery)
+		if q.problem() != "" {
+			return checkoutOption{}, false
+		}
+		opt.Quotes = append(opt.Quotes, q)
+	}
+	return opt, true
+}
+
+// checkoutRequests turns the selected checkout option into one order per store.
+func (m model) checkoutRequests(opt checkoutOption) []OrderRequest {
+	var reqs []OrderRequest
+	d, slot := m.selectedDelivery(), m.selectedSlot()
+	for _, q := range opt.Quotes {
+		reqs = append(reqs, OrderRequest{
+			UserID:      m.token,
+			Lines:       q.Lines,
+			Store:       q.Store.Name,
+			Delivery:    d.label(),
+			DeliveryFee: q.Fee,
+			Slot:        slot,
+		})
+	}
+	return reqs
+}
+
+// --- PLACING ---
+
+// checkoutState tracks the orders of one checkout, one per store, until every
+// store has replied.
+type checkoutState struct {
+	pending int
+	placed  []string // stores that took their order
+	failed  []string // "store: error" for the others
+}
+
+// checkoutPlacedMsg is one store's answer; err is set when its order failed.
+type checkoutPlacedMsg struct {
+	req   OrderRequest
+	order Order
+	err   error
+}
+
+func placeCheckoutCmd(ctx context.Context, p OrderProvider, req OrderRequest) tea.Cmd {
+	return func() tea.Msg {
+		o, err := p.PlaceOrder(ctx, req)
+		return checkoutPlacedMsg{req, o, err}
+	}
+}
+
+// startCheckout places the option's orders, all at once.
+func (m *model) startCheckout(opt checkoutOption) tea.Cmd {
+	reqs := m.checkoutRequests(opt)
+	m.checkout = &checkoutState{pending: len(reqs)}
+	var cmds []tea.Cmd
+	for _, req := range reqs {
+		cmds = append(cmds, placeCheckoutCmd(m.ctx, m.orderProvider, req))
+	}
+	m.state = stateProcessingBuy
+	return tea.Batch(cmds...)
+}
+
+// checkoutPlaced records one store's answer. Placed lines leave the cart right
+// away; the checkout screen comes back only once every store has replied, with
+// just the failed stores' lines left in the cart.
+func (m *model) checkoutPlaced(msg checkoutPlacedMsg) tea.Cmd {
+	store := msg.req.Store
+	if store == "" {
+		store = "the store"
+	}
+	var cmd tea.Cmd
+	if msg.err == nil {
+		m.takeFromCart(msg.req.Lines)
+		cmd = m.trackOrder(msg.order)
+	}
+	c := m.checkout
+	if c == nil {
+		return cmd
+	}
+	if msg.err != nil {
+		c.failed = append(c.failed, store+": "+msg.err.Error())
+	} else {
+		c.placed = append(c.placed, store)
+	}
+	if c.pending--; c.pending > 0 {
+		return cmd
+	}
+
+	m.checkout = nil
+	m.cursor = 0
+	if len(c.failed) > 0 {
+		m.state = stateFoodBuy
+		m.statusMsg = "❌ Order failed at " + strings.Join(c.failed, "; ")
+		if len(c.placed) > 0 {
+			m.statusMsg += " • placed at " + strings.Join(c.placed, ", ") + "; only the failed items are left in the cart"
+		}
+		return cmd
+	}
+	m.state = stateOrders
+	m.statusMsg = "Order placed! Track it here 🚚"
+	if len(c.placed) > 1 {
+		m.statusMsg = fmt.Sprintf("%d orders placed (%s)! Track them here 🚚", len(c.placed), strings.Join(c.placed, ", "))
+	}
+	return cmd
+}
+
+// takeFromCart lowers the cart by the ordered lines.
+func (m *model) takeFromCart(lines []OrderLine) {
+	for _, l := range lines {
+		for i := range m.foodItems {
+			if m.foodItems[i].ID == l.ItemID {
+				m.foodItems[i].CartQty = max(roundQty(m.foodItems[i].CartQty-l.Qty), 0)
+			}
+		}
+	}
+}
+
+// --- VIEW ---
+func (m model) viewCheckout() string {
+	s := titleStyle.Render("🚚 CHECKOUT") + "\n"
+	items := m.cartItems()
+	if len(items) == 0 {
+		s += boxStyle.Render("🛒 Cart empty.\nGo back and press Right Arrow to add items to cart.")
+		return s + "\n\n" + hintStyle.Render("[Esc: Cancel]")
+	}
+
+	var cart []string
+	for _, f := range items {
+		cart = append(cart, qtyLabel(f.CartQty, f.Unit)+" "+f.Name)
+	}
+	s += "Items in Cart: " + strings.Join(cart, ", ") + "\n\n"
+
+	for i, d := range m.deliveryOptions() {
+		c := d.label() + " (" + d.feeText() + ")"
+		if i == m.buyChoice {
+			s += selStyle.Render("["+c+"]") + " "
+		} else {
+			s += hintStyle.Render(" "+c+" ") + " "
+		}
+	}
+	s += "\n"
+	if slot := m.selectedSlot(); slot != nil {
+		s += "⏰ " + slot.String() + hintStyle.Render("  (left/right: other slots)") + "\n\n"
+	} else if len(m.selectedDelivery().Slots) > 0 {
+		s += "⏰ No slots left in the next few days; it will come as soon as possible.\n\n"
+	} else {
+		s += "⏰ As soon as possible\n\n"
+	}
+
+	opts := m.checkoutOptions()
+	for i, o := range opts {
+		cursor := "  "
+		if m.cursor == i {
+			cursor = "▶ "
+		}
+		var names []string
+		for _, q := range o.Quotes {
+			names = append(names, q.Store.Name)
+		}
+		label := "🏬 " + names[0]
+		if o.Split {
+			label = "✂️  Split: " + strings.Join(names, " + ")
+		}
+		line := fmt.Sprintf("  %s %-34s $%7.2f", cursor, label, o.total())
+		if p := o.problem(); p != "" {
+			line = fmt.Sprintf("  %s %-34s ⚠️ %s", cursor, label, p)
+		}
+		if m.cursor == i {
+			s += selStyle.Render(line) + "\n"
+		} else {
+			s += itemStyle.Render(line) + "\n"
+		}
+	}
+
+	if m.cursor < len(opts) {
+		o := opts[m.cursor]
+		var detail string
+		for i, q := range o.Quotes {
+			if i > 0 {
+				detail += "\n\n"
+			}
+			if o.Split {
+				detail += "🏬 " + q.Store.Name + "\n"
+			}
+			for _, l := range q.Lines {
+				detail += fmt.Sprintf("  %-8s %-15s - $%.2f\n", qtyLabel(l.Qty, l.Unit), l.Name, l.UnitPrice*l.Qty)
+			}
+			if q.Fee > 0 {
+				detail += fmt.Sprintf("  Subtotal $%.2f + delivery $%.2f", q.Subtotal, q.Fee)
+			} else {
+				detail += fmt.Sprintf("  Subtotal $%.2f", q.Subtotal)
+			}
+		}
+		s += "\n" + boxStyle.Render(detail) + "\n"
+		if o.problem() == "" {
+			s += fmt.Sprintf("\n💰 TOTAL TO PAY: $%.2f\n", o.total())
+		}
+	}
+	s += "\n" + hintStyle.Render("[up/down: Store • Tab: Delivery option • left/right: Time slot • Enter: Buy • Esc: Cancel]")
+	return s
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	pickup   = DeliveryOption{Name: "Pick Up", Pickup: true}
+	delivery = DeliveryOption{Name: "Delivery", Fee: 3}
+
+	storeA = Store{Name: "A", Prices: map[string]float64{"milk": 1, "eggs": 5}}
+	storeB = Store{Name: "B", Prices: map[string]float64{"Milk": 3, "Eggs": 2}}
+	storeC = Store{Name: "C", Prices: map[string]float64{"bread": 1}}
+
+	cart = []FoodItem{{ID: "m", Name: "Milk", CartQty: 1}, {ID: "e", Name: "Eggs", CartQty: 1}}
+)
+
+// stores lists the store names of an option, in quote order.
+func stores(opt checkoutOption) string {
+	var names []string
+	for _, q := range opt.Quotes {
+		names = append(names, q.Store.Name)
+	}
+	return strings.Join(names, "+")
+}
+
+func TestAssignCheapest(t *testing.T) {
+	all := []Store{storeA, storeB, storeC}
+	minB := storeB
+	minB.MinOrder = 10
+
+	tests := []struct {
+		name   string
+		stores []Store
+		picked []int
+		d      DeliveryOption
+		want   string // "" when the split is not possible
+	}{
+		{"each item to its cheapest store", all, []int{0, 1}, pickup, "A+B"},
+		{"a store with nothing to order", all, []int{0, 2}, pickup, ""},
+		{"an item nobody sells", all, []int{2}, pickup, ""},
+		{"below a delivery minimum", []Store{storeA, minB}, []int{0, 1}, delivery, ""},
+		{"pickups have no minimum", []Store{storeA, minB}, []int{0, 1}, pickup, "A+B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, ok := assignCheapest(tt.stores, tt.picked, cart, tt.d)
+			if ok != (tt.want != "") || (ok && stores(opt) != tt.want) {
+				t.Fatalf("got %q, %v; want %q", stores(opt), ok, tt.want)
+			}
+			if ok && (opt.Quotes[0].Lines[0].Name != "Milk" || opt.Quotes[1].Lines[0].Name != "Eggs") {
+				t.Errorf("lines = %+v", opt.Quotes)
+			}
+		})
+	}
+}
+
+func TestSplitCart(t *testing.T) {
+	opt, ok := splitCart([]Store{storeC, storeA, storeB}, cart, pickup)
+	if !ok || stores(opt) != "A+B" || opt.total() != 3 {
+		t.Fatalf("split = %q for $%.2f (%v), want A+B for $3", stores(opt), opt.total(), ok)
+	}
+	if _, ok := splitCart([]Store{storeA}, cart, pickup); ok {
+		t.Error("split with a single store")
+	}
+
+	// Two delivery fees make the split dearer than buying everything at B
+	m := model{stores: []Store{storeA, storeB}, foodItems: cart, deliveryOpts: []DeliveryOption{delivery}}
+	if opts := m.checkoutOptions(); opts[0].Split || stores(opts[0]) != "B" {
+		t.Errorf("first option is %q (split %v), want B", stores(opts[0]), opts[0].Split)
+	}
+	m.deliveryOpts = []DeliveryOption{pickup}
+	if opts := m.checkoutOptions(); !opts[0].Split {
+		t.Errorf("first option is %q, want the split", stores(opts[0]))
+	}
+}
+
+// failingOrders fails every order placed at one store.
+type failingOrders struct {
+	*mockOrderProvider
+	store string
+}
+
+func (p failingOrders) PlaceOrder(ctx context.Context, req OrderRequest) (Order, error) {
+	if req.Store == p.store {
+		return Order{}, errors.New("card declined")
+	}
+	return p.mockOrderProvider.PlaceOrder(ctx, req)
+}
+
+func TestSplitCheckoutPartialFailure(t *testing.T) {
+	orders := failingOrders{newMockOrderProvider(), "B"}
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), orders, nil, &localStore{}, "u")
+	m.stores = []Store{storeA, storeB}
+	m.deliveryOpts = []DeliveryOption{pickup}
+	m.foodItems = append([]FoodItem(nil), cart...)
+
+	opt := m.checkoutOptions()[0]
+	reqs := m.checkoutRequests(opt)
+	m.startCheckout(opt)
+	for i, req := range reqs {
+		msg := placeCheckoutCmd(m.ctx, orders, req)().(checkoutPlacedMsg)
+		next, _ := m.Update(msg)
+		m = next.(model)
+		if last := i == len(reqs)-1; (m.state == stateProcessingBuy) == last {
+			t.Fatalf("after %d of %d replies the state is %v", i+1, len(reqs), m.state)
+		}
+	}
+
+	if m.state != stateFoodBuy {
+		t.Fatalf("state = %v, want back at checkout", m.state)
+	}
+	if m.foodItems[0].CartQty != 0 || m.foodItems[1].CartQty != 1 {
+		t.Errorf("cart: milk %v, eggs %v; want only the failed eggs left", m.foodItems[0].CartQty, m.foodItems[1].CartQty)
+	}
+	if !strings.Contains(m.statusMsg, "B: card declined") || !strings.Contains(m.statusMsg, "placed at A") {
+		t.Errorf("status = %q", m.statusMsg)
+	}
+	if len(m.store.Orders) != 1 || m.store.Orders[0].Store != "A" {
+		t.Errorf("tracked orders = %+v", m.store.Orders)
+	}
+}
+
+func TestOrderPlacedClearsRequestedLines(t *testing.T) {
+	m := initialModel(context.Background(), Config{}, newFakeAPI(), newMockOrderProvider(), nil, &localStore{}, "u")
+	m.foodItems = append([]FoodItem(nil), cart...)
+	req := OrderRequest{Lines: []OrderLine{{ItemID: "m", Name: "Milk", Qty: 1}}}
+
+	// The provider answers without the lines
+	next, _ := m.Update(orderPlacedMsg{order: Order{ID: "o1"}, req: req})
+	m = next.(model)
+	if m.foodItems[0].CartQty != 0 || m.foodItems[1].CartQty != 1 {
+		t.Errorf("cart: milk %v, eggs %v", m.foodItems[0].CartQty, m.foodItems[1].CartQty)
+	}
+}
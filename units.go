@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// --- UNITS ---
+// Stock is counted in one unit per item: pieces (the default), grams,
+// kilograms, millilitres or litres. Amounts may be fractional ("1.5 kg").
+// Price is per unit of stock unless PackSize is set, in which case it is the
+// price of one package holding PackSize units.
+const unitPieces = "pcs"
+
+// stockUnits are the units a FoodItem can be counted in.
+var stockUnits = []string{unitPieces, "g", "kg", "ml", "L"}
+
+// unitScale expresses each unit in its base unit (g or ml) so that amounts
+// can be converted; recipe-only units like cups are not convertible.
+var unitScale = map[string]struct {
+	base   string
+	factor float64
+}{
+	"g": {"g", 1}, "kg": {"g", 1000}, "oz": {"g", 28.3495}, "lb": {"g", 453.592},
+	"ml": {"ml", 1}, "L": {"ml", 1000},
+}
+
+// canonicalUnit maps any spelling of a unit (including the recipe ones) to
+// its canonical form; "" and unknown words are returned unchanged.
+func canonicalUnit(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "pc", "pcs", "x", "piece", "pieces":
+		return unitPieces
+	case "l":
+		return "L"
+	}
+	if u, ok := recipeUnits[s]; ok {
+		if u == "l" {
+			return "L"
+		}
+		return u
+	}
+	return s
+}
+
+// parseUnit reads a stock unit from the food form; "" means pieces.
+func parseUnit(s string) (string, error) {
+	u := canonicalUnit(s)
+	for _, su := range stockUnits {
+		if u == su {
+			return u, nil
+		}
+	}
+	return "", fmt.Errorf("unknown unit %q (use %s)", strings.TrimSpace(s), strings.Join(stockUnits, ", "))
+}
+
+// convertQty converts q from one unit to another of the same kind (mass or volume).
+func convertQty(q float64, from, to string) (float64, bool) {
+	from, to = canonicalUnit(from), canonicalUnit(to)
+	if from == to {
+		return q, true
+	}
+	f, ok1 := unitScale[from]
+	t, ok2 := unitScale[to]
+	if !ok1 || !ok2 || f.base != t.base {
+		return 0, false
+	}
+	return roundQty(q * f.factor / t.factor), true
+}
+
+// roundQty keeps quantities to three decimals so repeated float arithmetic
+// does not leave amounts like 0.30000000000000004.
+func roundQty(q float64) float64 {
+	return math.Round(q*1000) / 1000
+}
+
+// fmtQty prints a quantity without trailing zeros: "3", "1.5", "0.25".
+func fmtQty(q float64) string {
+	return strconv.FormatFloat(roundQty(q), 'f', -1, 64)
+}
+
+// qtyLabel prefixes an item name in lists: "3x" for pieces, "1.5 kg" otherwise.
+func qtyLabel(q float64, unit string) string {
+	if u := canonicalUnit(unit); u != unitPieces {
+		return fmtQty(q) + " " + u
+	}
+	return fmtQty(q) + "x"
+}
+
+// amountText is a stock amount on its own: "3" for pieces, "1.5 kg" otherwise.
+func amountText(q float64, unit string) string {
+	if u := canonicalUnit(unit); u != unitPieces {
+		return fmtQty(q) + " " + u
+	}
+	return fmtQty(q)
+}
+
+func (f FoodItem) unit() string {
+	return canonicalUnit(f.Unit)
+}
+
+// unitPrice is the price of one unit of stock, which is what order lines and
+// totals are computed from.
+func (f FoodItem) unitPrice() float64 {
+	if f.PackSize > 0 {
+		return f.Price / f.PackSize
+	}
+	return f.Price
+}
+
+// priceText shows the price the way it was entered: "$1.20", "$2.40/kg" or
+// "$1.10/500 g".
+func (f FoodItem) priceText() string {
+	switch {
+	case f.PackSize > 0:
+		return fmt.Sprintf("$%.2f/%s %s", f.Price, fmtQty(f.PackSize), f.unit())
+	case f.unit() != unitPieces:
+		return fmt.Sprintf("$%.2f/%s", f.Price, f.unit())
+	}
+	return fmt.Sprintf("$%.2f", f.Price)
+}
+
+// cartStep is how much one press of Right/Left adds or removes: a package
+// when the item is sold in packages, 100 g/ml for small units, else 1.
+func (f FoodItem) cartStep() float64 {
+	if f.PackSize > 0 {
+		return f.PackSize
+	}
+	if u := f.unit(); u == "g" || u == "ml" {
+		return 100
+	}
+	return 1
+}
+
+// cost is what q units of the item cost.
+func (f FoodItem) cost(q float64) float64 {
+	return f.unitPrice() * q
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestConvertQty(t *testing.T) {
+	tests := []struct {
+		q        float64
+		from, to string
+		want     float64
+		ok       bool
+	}{
+		{1.5, "kg", "g", 1500, true},
+		{250, "g", "kg", 0.25, true},
+		{500, "ml", "L", 0.5, true},
+		{2, "litres", "ml", 2000, true},
+		{1, "l", "L", 1, true},
+		{8, "oz", "g", 226.796, true},
+		{1, "pound", "kg", 0.454, true},
+		{3, "", "pcs", 3, true},
+		{2, "pieces", "pcs", 2, true},
+		{1, "cup", "cups", 1, true},
+		{1, "cup", "ml", 0, false},
+		{1, "kg", "L", 0, false},
+		{2, "pcs", "g", 0, false},
+		{1, "tbsp", "g", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := convertQty(tt.q, tt.from, tt.to)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("convertQty(%v, %q, %q) = %v, %v; want %v, %v", tt.q, tt.from, tt.to, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseUnit(t *testing.T) {
+	for in, want := range map[string]string{"": "pcs", "PCS": "pcs", " kg ": "kg", "l": "L", "grams": "g", "litre": "L"} {
+		if got, err := parseUnit(in); err != nil || got != want {
+			t.Errorf("parseUnit(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"cup", "oz", "bag"} {
+		if _, err := parseUnit(in); err == nil {
+			t.Errorf("parseUnit(%q) accepted a unit stock cannot be kept in", in)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- FORM VALIDATION ---
+// The food and subscription forms are checked as a whole before anything is
+// saved. Every invalid input gets its own message, shown under it with its
+// prompt in red, and the form stays open with the first one focused.
+
+// fieldErrors maps an input index to what is wrong with it.
+type fieldErrors map[int]string
+
+// add records msg for field i, keeping the first problem found.
+func (e fieldErrors) add(i int, msg string) {
+	if _, ok := e[i]; !ok {
+		e[i] = msg
+	}
+}
+
+// fields lists the invalid fields top to bottom.
+func (e fieldErrors) fields() []int {
+	fields := make([]int, 0, len(e))
+	for i := range e {
+		fields = append(fields, i)
+	}
+	sort.Ints(fields)
+	return fields
+}
+
+// first is the topmost invalid field.
+func (e fieldErrors) first() int {
+	return e.fields()[0]
+}
+
+func (e fieldErrors) Error() string {
+	if len(e) == 1 {
+		return e[e.first()]
+	}
+	return fmt.Sprintf("%d fields need fixing", len(e))
+}
+
+// numberField reads an optional non-negative number; empty means 0.
+func numberField(e fieldErrors, i int, s string) float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+	switch {
+	case err != nil || math.IsNaN(v) || math.IsInf(v, 0):
+		// ParseFloat accepts "NaN" and "inf", which json.Marshal then refuses
+		e.add(i, fmt.Sprintf("%q is not a number", s))
+		return 0
+	case v < 0:
+		e.add(i, "can't be negative")
+	}
+	return max(roundQty(v), 0)
+}
+
+// nameField reads a required name that no other item (but the one at skip) uses.
+func nameField(e fieldErrors, i int, s string, names []string, skip int) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		e.add(i, "a name is required")
+		return s
+	}
+	for j, n := range names {
+		if j != skip && strings.EqualFold(strings.TrimSpace(n), s) {
+			e.add(i, fmt.Sprintf("%q already exists", n))
+			break
+		}
+	}
+	return s
+}
+
+// validateFoodForm reads the food form into an item (without ID or cart).
+func (m model) validateFoodForm() (FoodItem, fieldErrors) {
+	vals := make([]string, foodFieldCount)
+	for i := range vals {
+		vals[i] = m.inputs[i].Value()
+	}
+	return m.validateFood(vals, m.reorderChoice)
+}
+
+// validateFood checks the values of the food form fields (indexed like
+// m.inputs), so the CLI can share the form's rules.
+func (m model) validateFood(vals []string, reorderChoice int) (FoodItem, fieldErrors) {
+	e := make(fieldErrors)
+	val := func(i int) string { return vals[i] }
+
+	names := make([]string, len(m.foodItems))
+	for j, f := range m.foodItems {
+		names[j] = f.Name
+	}
+	f := FoodItem{
+		Name:           nameField(e, foodName, val(foodName), names, m.editIndex),
+		Price:          numberField(e, foodPrice, val(foodPrice)),
+		Amount:         numberField(e, foodAmount, val(foodAmount)),
+		RenewThreshold: numberField(e, foodThreshold, val(foodThreshold)),
+		ReorderQty:     numberField(e, foodReorderQty, val(foodReorderQty)),
+		PackSize:       numberField(e, foodPackSize, val(foodPackSize)),
+		Aisle:          strings.ToLower(strings.TrimSpace(val(foodAisle))),
+		Storage:        strings.ToLower(strings.TrimSpace(val(foodStorage))),
+		Barcode:        strings.TrimSpace(val(foodBarcode)),
+	}
+
+	unit, err := parseUnit(val(foodUnit))
+	if err != nil {
+		e.add(foodUnit, err.Error())
+	}
+	f.Unit = unit
+
+	if _, bad := e[foodAmount]; !bad {
+		if f.Batches, err = parseBatches(val(foodExpiry), f.Amount); err != nil {
+			e.add(foodExpiry, err.Error())
+		}
+	}
+
+	if f.Barcode != "" {
+		code := normalizeBarcode(f.Barcode)
+		if code == "" || strings.Trim(f.Barcode, "0123456789 -") != "" {
+			e.add(foodBarcode, "a barcode is digits only")
+		}
+		for j, other := range m.foodItems {
+			if j != m.editIndex && other.Barcode != "" && normalizeBarcode(other.Barcode) == code {
+				e.add(foodBarcode, "already used by "+other.Name)
+				break
+			}
+		}
+	}
+
+	rule := reorderChoices[reorderChoice]
+	f.ReorderAction, f.ReorderDelivery = rule.action, rule.delivery
+	return f, e
+}
+
+// validateSubForm reads the subscription form; the due date is as typed.
+func (m model) validateSubForm() (SubItem, fieldErrors) {
+	e := make(fieldErrors)
+	names := make([]string, len(m.subItems))
+	for j, s := range m.subItems {
+		names[j] = s.Name
+	}
+	sub := SubItem{
+		Name:  nameField(e, 0, m.inputs[0].Value(), names, m.editIndex),
+		Cycle: m.subCycleChoices[m.subCycleChoice],
+	}
+	if strings.TrimSpace(m.inputs[1].Value()) == "" {
+		e.add(1, "a price is required")
+	}
+	sub.Price = numberField(e, 1, m.inputs[1].Value())
+
+	date, err := parseDate(m.inputs[2].Value())
+	if err != nil {
+		e.add(2, err.Error())
+	}
+	sub.DueDate = date
+	return sub, e
+}
+
+// rejectForm shows errs on the form and focuses the first invalid input.
+func (m *model) rejectForm(errs fieldErrors) error {
+	m.fieldErrs = errs
+	m.setFocus(errs.first())
+	return errs
+}
+
+// fieldEdited drops the message of input i once its value changes.
+func (m *model) fieldEdited(i int) {
+	if _, bad := m.fieldErrs[i]; !bad {
+		return
+	}
+	delete(m.fieldErrs, i)
+	m.inputs[i].PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	if len(m.fieldErrs) == 0 {
+		m.formErr = ""
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestValidateFoodForm(t *testing.T) {
+	pantry := []FoodItem{{ID: "m", Name: "Milk", Barcode: "3017620422003"}, {ID: "e", Name: "Eggs"}}
+
+	tests := []struct {
+		name    string
+		edit    int // editIndex; -1 adds a new item
+		fields  map[int]string
+		invalid []int
+	}{
+		{"valid", -1, map[int]string{foodName: "Flour", foodPrice: "$1.20", foodAmount: "1.5", foodUnit: "kg", foodExpiry: "1@2026-11-02"}, nil},
+		{"empty form", -1, nil, []int{foodName}},
+		{"duplicate name", -1, map[int]string{foodName: " milk "}, []int{foodName}},
+		{"own name when editing", 0, map[int]string{foodName: "Milk", foodBarcode: "3017620422003"}, nil},
+		{"NaN and inf", -1, map[int]string{foodName: "Flour", foodPrice: "NaN", foodAmount: "inf", foodPackSize: "-Inf"}, []int{foodPrice, foodAmount, foodPackSize}},
+		{"not numbers", -1, map[int]string{foodName: "Flour", foodThreshold: "a few", foodReorderQty: "-1"}, []int{foodThreshold, foodReorderQty}},
+		{"unknown unit", -1, map[int]string{foodName: "Flour", foodUnit: "cups"}, []int{foodUnit}},
+		{"expiry beyond stock", -1, map[int]string{foodName: "Flour", foodAmount: "1", foodExpiry: "2@2026-11-02"}, []int{foodExpiry}},
+		{"bad expiry date", -1, map[int]string{foodName: "Flour", foodAmount: "1", foodExpiry: "2026-13-40"}, []int{foodExpiry}},
+		{"barcode letters", -1, map[int]string{foodName: "Flour", foodBarcode: "ABC123"}, []int{foodBarcode}},
+		{"barcode taken", -1, map[int]string{foodName: "Spread", foodBarcode: "03017620422003"}, []int{foodBarcode}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{foodItems: pantry, editIndex: tt.edit}
+			m.initForm(stateAddFood, false)
+			for i, v := range tt.fields {
+				m.inputs[i].SetValue(v)
+			}
+
+			item, errs := m.validateFoodForm()
+			var got []int
+			for i := range errs {
+				got = append(got, i)
+			}
+			sort.Ints(got)
+			if len(got) != len(tt.invalid) {
+				t.Fatalf("invalid fields = %v (%v), want %v", got, errs, tt.invalid)
+			}
+			for n := range got {
+				if got[n] != tt.invalid[n] {
+					t.Fatalf("invalid fields = %v (%v), want %v", got, errs, tt.invalid)
+				}
+			}
+			if len(errs) == 0 {
+				if _, err := json.Marshal(item); err != nil {
+					t.Errorf("valid item does not encode: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldErrorsFirst(t *testing.T) {
+	e := fieldErrors{foodUnit: "x", foodPrice: "y", foodExpiry: "z"}
+	if e.first() != foodPrice {
+		t.Errorf("first = %d, want %d", e.first(), foodPrice)
+	}
+	if e.Error() != "3 fields need fixing" {
+		t.Errorf("Error() = %q", e.Error())
+	}
+}